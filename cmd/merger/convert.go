@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/johandry/merger"
+)
+
+// convert prints the configuration file in another format
+func convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "input format: json, yaml, toml, env or properties. By default, the file extension")
+	to := fs.String("to", string(merger.JSON), "output format: json, yaml, toml, env or properties")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, closeInput, err := input(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	format := merger.Format(*from)
+	if len(format) == 0 {
+		if fs.NArg() == 0 || fs.Arg(0) == "-" {
+			return fmt.Errorf("the input format is required to read stdin")
+		}
+		if format, err = merger.FormatOf(fs.Arg(0)); err != nil {
+			return err
+		}
+	}
+
+	b, err := merger.Convert(r, format, merger.Format(*to))
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(b)
+	return err
+}
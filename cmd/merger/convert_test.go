@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml": "db:\n  host: localhost\n  port: 5432\nhosts: [a, b]\n",
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    string
+		wantErr bool
+	}{
+		{name: "yaml file to toml",
+			args: []string{"convert", "-to", "toml", filepath.Join(dir, "app.yaml")},
+			want: "hosts = [\"a\", \"b\"]\n\n[db]\nhost = \"localhost\"\nport = 5432\n",
+		},
+		{name: "stdin env to properties",
+			args:  []string{"convert", "-from", "env", "-to", "properties"},
+			stdin: "DB__HOST=localhost\nNAME=my app\n",
+			want:  "DB__HOST=localhost\nNAME=my app\n",
+		},
+		{name: "stdin without format",
+			args:    []string{"convert", "-to", "yaml"},
+			stdin:   "{}",
+			wantErr: true,
+		},
+		{name: "unknown output format",
+			args:    []string{"convert", "-from", "json", "-to", "xml"},
+			stdin:   "{}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.stdin), &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
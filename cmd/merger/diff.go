@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/johandry/merger"
+)
+
+// diff prints the differences of the parameters of two files
+func diff(args []string, stdin io.Reader, stdout io.Writer) error {
+	var kf keyFlags
+	var secrets listFlag
+	fs := newFlagSet("diff", &kf)
+	fs.Var(&secrets, "secret", "pattern of the keys with secret values, it can be used many times")
+	output := fs.String("o", "text", "output format: text or json")
+	exitCode := fs.Bool("exit-code", false, "exit with 1 if there are differences")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("expected two files, found %d", fs.NArg())
+	}
+
+	a, err := loadFile(fs.Arg(0), kf.separator, stdin)
+	if err != nil {
+		return err
+	}
+	b, err := loadFile(fs.Arg(1), kf.separator, stdin)
+	if err != nil {
+		return err
+	}
+
+	patterns := newSecretPatterns(secrets)
+	changes := merger.DiffMaps(a.vars, b.vars)
+	for i, c := range changes {
+		if c.Kind != merger.Added {
+			changes[i].Old = patterns.redact(c.Path, c.Old)
+		}
+		if c.Kind != merger.Removed {
+			changes[i].New = patterns.redact(c.Path, c.New)
+		}
+	}
+
+	switch *output {
+	case "text":
+		_, err = io.WriteString(stdout, merger.DiffText(changes))
+	case "json":
+		var b []byte
+		if b, err = merger.DiffJSON(changes); err == nil {
+			_, err = fmt.Fprintf(stdout, "%s\n", b)
+		}
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *exitCode && len(changes) != 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "db:\n  host: localhost\n  password: a-pass\nname: app\n",
+		"b.json": `{"db": {"host": "db.prod", "password": "b-pass"}, "debug": true}`,
+	})
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.json")
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "text",
+			args: []string{"diff", a, b},
+			want: "~ db__host = localhost -> db.prod\n~ db__password = ***** -> *****\n+ debug = true\n- name = app\n",
+		},
+		{name: "json with exit code",
+			args: []string{"diff", "-o", "json", "-exit-code", "-secret", "name", a, b},
+			want: `[
+  {
+    "path": "db__host",
+    "old": "localhost",
+    "new": "db.prod",
+    "kind": "modified"
+  },
+  {
+    "path": "db__password",
+    "old": "a-pass",
+    "new": "b-pass",
+    "kind": "modified"
+  },
+  {
+    "path": "debug",
+    "old": "",
+    "new": "true",
+    "kind": "added"
+  },
+  {
+    "path": "name",
+    "old": "*****",
+    "new": "",
+    "kind": "removed"
+  }
+]
+`,
+			wantCode: 1,
+		},
+		{name: "no differences",
+			args: []string{"diff", "-exit-code", a, a},
+			want: "",
+		},
+		{name: "one file",
+			args:    []string{"diff", a},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(""), &stdout)
+
+			var ee *exitError
+			switch {
+			case errors.As(err, &ee):
+				if ee.code != tt.wantCode {
+					t.Errorf("run() exit code = %d, want %d", ee.code, tt.wantCode)
+				}
+			case (err != nil) != tt.wantErr:
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			case tt.wantCode != 0:
+				t.Errorf("run() exit code = 0, want %d", tt.wantCode)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
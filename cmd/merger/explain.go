@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// explain prints every parameter with its final value and the layer setting
+// it, followed by the values of the lower layers shadowed by it. The files
+// are merged in order, the last one has the highest priority, and the
+// environment variables, if used, have the highest priority
+func explain(args []string, stdin io.Reader, stdout io.Writer) error {
+	var kf keyFlags
+	var files, secrets listFlag
+	fs := newFlagSet("explain", &kf)
+	fs.Var(&files, "file", "configuration file, it can be used many times, the last one has the highest priority")
+	env := fs.Bool("env", false, "use the environment variables with the prefix (required) as the layer with the highest priority")
+	fs.Var(&secrets, "secret", "pattern of the keys with secret values, it can be used many times")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files = append(files, fs.Args()...)
+
+	layers, err := loadLayers(files, *env, kf, stdin)
+	if err != nil {
+		return err
+	}
+	patterns := newSecretPatterns(secrets)
+
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, l := range layers {
+		for k := range l.vars {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		winner := true
+		for i := len(layers) - 1; i >= 0; i-- {
+			v, ok := layers[i].vars[k]
+			if !ok {
+				continue
+			}
+			v = patterns.redact(k, v)
+			if winner {
+				_, err = fmt.Fprintf(stdout, "%s=%q from %s\n", k, v, layers[i].name)
+				winner = false
+			} else {
+				_, err = fmt.Fprintf(stdout, "  shadows %q from %s\n", v, layers[i].name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
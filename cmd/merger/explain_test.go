@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExplain(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml": "db:\n  host: localhost\n  password: base-pass\nname: app\n",
+		"prod.json": `{"db": {"host": "db.prod", "password": "prod-pass"}}`,
+		"local.env": "DB__HOST=127.0.0.1\nAPI_KEY=abc\n",
+	})
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.json")
+	local := filepath.Join(dir, "local.env")
+	t.Setenv("APP_NAME", "from-env")
+	t.Setenv("APP_DB__HOST", "db.env")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "files and env",
+			args: []string{"explain", "-env", "-prefix", "APP_", "-file", base, prod},
+			want: `db__host="db.env" from env
+  shadows "db.prod" from ` + prod + `
+  shadows "localhost" from ` + base + `
+db__password="*****" from ` + prod + `
+  shadows "*****" from ` + base + `
+name="from-env" from env
+  shadows "app" from ` + base + `
+`,
+		},
+		{name: "env with separator",
+			args: []string{"explain", "-env", "-prefix", "APP_", "-separator", ".", base},
+			want: `db.host="db.env" from env
+  shadows "localhost" from ` + base + `
+db.password="*****" from ` + base + `
+name="from-env" from env
+  shadows "app" from ` + base + `
+`,
+		},
+		{name: "env without prefix",
+			args:    []string{"explain", "-env", base},
+			wantErr: true,
+		},
+		{name: "dotenv and secret patterns",
+			args: []string{"explain", "-secret", "*_key", base, local},
+			want: `api_key="*****" from ` + local + `
+db__host="127.0.0.1" from ` + local + `
+  shadows "localhost" from ` + base + `
+db__password="base-pass" from ` + base + `
+name="app" from ` + base + `
+`,
+		},
+		{name: "missing file",
+			args:    []string{"explain", filepath.Join(dir, "none.yaml")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(""), &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// flatten prints the parameters of a JSON, YAML or .env document as KEY=value
+// lines, the nested keys are joined with the separator, like TransformToMap
+// does with the fields of a struct
+func flatten(args []string, stdin io.Reader, stdout io.Writer) error {
+	var kf keyFlags
+	fs := newFlagSet("flatten", &kf)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path := "-"
+	switch args := fs.Args(); {
+	case len(args) == 1:
+		path = args[0]
+	case len(args) > 1:
+		return fmt.Errorf("too many arguments %q, expected one file", args)
+	}
+
+	doc, err := readFile(path, stdin)
+	if err != nil {
+		return err
+	}
+	m, err := flattenDocument(doc, kf.separator)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(stdout, "%s%s=%s\n", kf.prefix, k, quoteValue(m[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valueString returns the value of a parameter like TransformToMap does, the
+// empty maps are "{}"
+func valueString(v interface{}) string {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				// Like in TransformToMap, only a list of simple values is a list
+				b, _ := json.Marshal(value)
+				return string(b)
+			}
+			items = append(items, scalarString(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return scalarString(value)
+	}
+}
+
+func scalarString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// quoteValue quotes the value if it can't be read back as it is
+func quoteValue(v string) string {
+	if strings.ContainsAny(v, "\n\r") || strings.TrimSpace(v) != v || strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "'") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	doc := `{
+  "name": "John",
+  "address": {"city": "Paris", "zip": 75001},
+  "books": ["a", "b"],
+  "grades": [{"math": 9}],
+  "note": " padded ",
+  "empty": {},
+  "nothing": null
+}`
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    string
+		wantErr bool
+	}{
+		{name: "defaults",
+			args:  []string{"flatten"},
+			stdin: doc,
+			want: `address__city=Paris
+address__zip=75001
+books=[a, b]
+empty={}
+grades=[{"math":9}]
+name=John
+note=" padded "
+nothing=
+`,
+		},
+		{name: "separator and prefix",
+			args:  []string{"flatten", "-separator", "_", "-prefix", "APP_", "-"},
+			stdin: `{"db": {"host": "localhost"}, "port": 80}`,
+			want:  "APP_db_host=localhost\nAPP_port=80\n",
+		},
+		{name: "invalid json",
+			args:    []string{"flatten"},
+			stdin:   `{"db"`,
+			wantErr: true,
+		},
+		{name: "key collision",
+			args:    []string{"flatten"},
+			stdin:   `{"db__host": "a", "db": {"host": "b"}}`,
+			wantErr: true,
+		},
+		{name: "missing file",
+			args:    []string{"flatten", "/does/not/exist.json"},
+			wantErr: true,
+		},
+		{name: "unknown command",
+			args:    []string{"compress"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.stdin), &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
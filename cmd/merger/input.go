@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/johandry/merger"
+)
+
+// readFile returns the document in the file, its format is defined by the
+// file extension, JSON if it's unknown. The file "-" is stdin, in JSON
+func readFile(path string, stdin io.Reader) (map[string]interface{}, error) {
+	if path == "-" {
+		return readDocument(stdin, merger.JSON)
+	}
+
+	format, err := merger.FormatOf(path)
+	if err != nil {
+		format = merger.JSON
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := readDocument(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file %s. %s", path, err)
+	}
+	return doc, nil
+}
+
+// readDocument returns the document in the given format. The parameters of
+// the flat formats, dotenv and properties, are not nested so they keep the
+// value as it is
+func readDocument(r io.Reader, format merger.Format) (map[string]interface{}, error) {
+	var vars map[string]string
+	var err error
+
+	switch format {
+	case merger.Dotenv:
+		vars, err = merger.ParseDotenv(r)
+	case merger.Properties:
+		vars, err = merger.ParseProperties(r)
+	default:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return merger.Unmarshal(data, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		doc[k] = v
+	}
+	return doc, nil
+}
+
+// flattenDocument returns the parameters of the document, the nested keys are
+// joined with the separator
+func flattenDocument(doc map[string]interface{}, separator string) (map[string]string, error) {
+	mrg := merger.New(merger.WithKeyCodec(merger.KeyCodec{Separator: separator}))
+	flat, err := mrg.FlattenMap(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(flat))
+	for k, v := range flat {
+		m[k] = valueString(v)
+	}
+	return m, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/johandry/merger"
+)
+
+// envLayer is the name of the layer with the environment variables
+const envLayer = "env"
+
+// defaultSecretPatterns are the patterns of the secret keys when none is given
+var defaultSecretPatterns = []string{"*password*", "*secret*", "*token*"}
+
+// layer is a source of parameters, the keys are in lower case because the
+// parameters are not case sensitive
+type layer struct {
+	name string
+	vars map[string]string
+}
+
+// loadLayers returns the parameters of the files and, if env is true, the
+// environment variables with the prefix, it's required so not every variable
+// is loaded. The keys of the environment variables don't have the prefix and
+// they are nested with FieldSeparator, like the dotenv files, so it's
+// replaced by the separator of the keys
+func loadLayers(files []string, env bool, kf keyFlags, stdin io.Reader) ([]layer, error) {
+	if env && len(kf.prefix) == 0 {
+		return nil, fmt.Errorf("the environment variables require a prefix, use -prefix")
+	}
+
+	layers := make([]layer, 0, len(files)+1)
+	for _, file := range files {
+		l, err := loadFile(file, kf.separator, stdin)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, l)
+	}
+
+	if env {
+		vars := map[string]string{}
+		for _, kv := range os.Environ() {
+			pair := strings.SplitN(kv, "=", 2)
+			if len(pair) == 2 && strings.HasPrefix(pair[0], kf.prefix) {
+				key := strings.Replace(strings.TrimPrefix(pair[0], kf.prefix), merger.FieldSeparator, kf.separator, -1)
+				vars[strings.ToLower(key)] = pair[1]
+			}
+		}
+		layers = append(layers, layer{name: envLayer, vars: vars})
+	}
+
+	return layers, nil
+}
+
+func loadFile(file, separator string, stdin io.Reader) (layer, error) {
+	doc, err := readFile(file, stdin)
+	if err != nil {
+		return layer{}, err
+	}
+	flat, err := flattenDocument(doc, separator)
+	if err != nil {
+		return layer{}, fmt.Errorf("%s: %s", file, err)
+	}
+	vars := map[string]string{}
+	for k, v := range flat {
+		vars[strings.ToLower(k)] = v
+	}
+	return layer{name: file, vars: vars}, nil
+}
+
+// secretPatterns are the patterns, like in path.Match, of the keys with secret
+// values. They are not case sensitive
+type secretPatterns []string
+
+func (p secretPatterns) isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range p {
+		if ok, _ := path.Match(strings.ToLower(pattern), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p secretPatterns) redact(key, value string) string {
+	if p.isSecret(key) {
+		return merger.RedactedValue
+	}
+	return value
+}
+
+func newSecretPatterns(patterns []string) secretPatterns {
+	if len(patterns) == 0 {
+		return defaultSecretPatterns
+	}
+	return patterns
+}
@@ -0,0 +1,141 @@
+// Command merger shows how the configuration files and environment variables
+// are interpreted by the merger package, so shell scripts and CI pipelines can
+// check them.
+//
+// Usage:
+//
+//	merger flatten [-separator __] [-prefix PREFIX] [file]
+//	merger unflatten [-separator __] [-prefix PREFIX] [-o json|yaml|toml|env|properties] [file]
+//	merger convert [-from json|yaml|toml|env|properties] [-to json|yaml|toml|env|properties] [file]
+//	merger run [-separator __] [-prefix PREFIX] [-file file]... -- command [args]
+//	merger explain [-separator __] [-prefix PREFIX] [-env] [-secret pattern]... [-file file]... [file...]
+//	merger diff [-separator __] [-secret pattern]... [-o text|json] [-exit-code] a b
+//
+// Without a file, or with the file "-", the input is read from stdin
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/johandry/merger"
+)
+
+// command is a subcommand of merger
+type command struct {
+	usage string
+	run   func(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"convert": {
+		usage: "print the configuration file in another format",
+		run:   convert,
+	},
+	"flatten": {
+		usage: "print the parameters of a JSON, YAML or .env file as KEY=value lines",
+		run:   flatten,
+	},
+	"unflatten": {
+		usage: "print the KEY=value lines, like a .env file, as a nested document",
+		run:   unflatten,
+	},
+	"diff": {
+		usage: "print the differences of the parameters of two files",
+		run:   diff,
+	},
+	"explain": {
+		usage: "print the value of every parameter, the layer setting it and the shadowed values",
+		run:   explain,
+	},
+	"run": {
+		usage: "run a command with the parameters of the files as environment variables",
+		run:   runCommand,
+	},
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	var ee *exitError
+	switch {
+	case errors.As(err, &ee):
+		os.Exit(ee.code)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "merger: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n\n%s", usage())
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage())
+	}
+	return cmd.run(args[1:], stdin, stdout)
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"Usage: merger <command> [options] [file]", "", "Commands:"}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %-10s %s", name, commands[name].usage))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keyFlags are the flags to name the parameters
+type keyFlags struct {
+	separator string
+	prefix    string
+}
+
+// listFlag is a flag that can be used many times
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func newFlagSet(name string, kf *keyFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&kf.separator, "separator", merger.FieldSeparator, "separator of the nested keys")
+	fs.StringVar(&kf.prefix, "prefix", "", "prefix of the keys")
+	return fs
+}
+
+// input returns the reader of the only file in args, stdin if there is no file
+// or it's "-". The returned function closes the file
+func input(args []string, stdin io.Reader) (io.Reader, func() error, error) {
+	noop := func() error { return nil }
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "-"):
+		return stdin, noop, nil
+	case len(args) > 1:
+		return nil, noop, fmt.Errorf("too many arguments %q, expected one file", args)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return nil, noop, err
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// exitError is returned when the child process exits with an error, merger
+// exits with the same code
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// runCommand merges the files, the last one has the highest priority, and
+// executes the command with the parameters as environment variables. The
+// variables are named like the flattened parameters, in upper case and with
+// the prefix. The variables already in the environment are not replaced, so
+// they keep the highest priority like with Merge
+func runCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	var kf keyFlags
+	var files listFlag
+	fs := newFlagSet("run", &kf)
+	fs.Var(&files, "file", "configuration file, it can be used many times, the last one has the highest priority")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("missing the command to run")
+	}
+
+	vars := map[string]string{}
+	for _, file := range files {
+		doc, err := readFile(file, stdin)
+		if err != nil {
+			return err
+		}
+		flat, err := flattenDocument(doc, kf.separator)
+		if err != nil {
+			return fmt.Errorf("%s: %s", file, err)
+		}
+		for k, v := range flat {
+			vars[kf.prefix+strings.ToUpper(k)] = v
+		}
+	}
+
+	env := os.Environ()
+	for k, v := range vars {
+		if _, ok := os.LookupEnv(k); !ok {
+			env = append(env, k+"="+v)
+		}
+	}
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Env = env
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	return execute(cmd)
+}
+
+// forwardedSignals are the signals received by merger and sent to the child
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// execute starts the command, forwards the signals received to it and waits
+// until it's done
+func execute(cmd *exec.Cmd) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	for {
+		select {
+		case sig := <-signals:
+			cmd.Process.Signal(sig)
+		case err := <-done:
+			var ee *exec.ExitError
+			if !errors.As(err, &ee) {
+				return err
+			}
+			if status, ok := ee.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				return &exitError{code: 128 + int(status.Signal())}
+			}
+			return &exitError{code: ee.ExitCode()}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.json")
+	if err := os.WriteFile(base, []byte("db:\n  host: localhost\n  port: 5432\nname: app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(prod, []byte(`{"db": {"host": "db.prod"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_NAME", "from-env")
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "layered files",
+			args: []string{"run", "-file", base, "--file", prod, "-prefix", "APP_", "--", "sh", "-c", "echo $APP_DB__HOST $APP_DB__PORT $APP_NAME"},
+			want: "db.prod 5432 from-env\n",
+		},
+		{name: "exit code",
+			args:     []string{"run", "-file", base, "--", "sh", "-c", "echo $DB__HOST; exit 3"},
+			want:     "localhost\n",
+			wantCode: 3,
+		},
+		{name: "missing command",
+			args:    []string{"run", "-file", base},
+			wantErr: true,
+		},
+		{name: "missing file",
+			args:    []string{"run", "-file", filepath.Join(dir, "none.yaml"), "--", "true"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(""), &stdout)
+
+			var ee *exitError
+			switch {
+			case errors.As(err, &ee):
+				if ee.code != tt.wantCode {
+					t.Errorf("run() exit code = %d, want %d", ee.code, tt.wantCode)
+				}
+			case (err != nil) != tt.wantErr:
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			case tt.wantCode != 0:
+				t.Errorf("run() exit code = 0, want %d", tt.wantCode)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/johandry/merger"
+)
+
+// unflatten prints the KEY=value lines as a nested document, like the one
+// returned by TransformMap. Only the keys with the prefix are used
+func unflatten(args []string, stdin io.Reader, stdout io.Writer) error {
+	var kf keyFlags
+	fs := newFlagSet("unflatten", &kf)
+	output := fs.String("o", string(merger.JSON), "output format: json, yaml, toml, env or properties")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, closeInput, err := input(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	vars, err := merger.ParseDotenv(r)
+	if err != nil {
+		return err
+	}
+
+	m := make(map[string]string, len(vars))
+	for k, v := range vars {
+		if !strings.HasPrefix(k, kf.prefix) {
+			continue
+		}
+		m[strings.TrimPrefix(k, kf.prefix)] = v
+	}
+
+	mrg := merger.New(merger.WithKeyCodec(merger.KeyCodec{Separator: kf.separator}))
+	b, err := mrg.Marshal(mrg.TransformMap(m), merger.Format(*output))
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(b)
+	return err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUnflatten(t *testing.T) {
+	env := `# Application settings
+export APP_NAME=John
+APP_ADDRESS__CITY="New York"
+APP_ADDRESS__ZIP=10001
+APP_BOOKS=[a, b]
+OTHER=ignored
+`
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    string
+		wantErr bool
+	}{
+		{name: "json",
+			args:  []string{"unflatten", "-prefix", "APP_"},
+			stdin: env,
+			want: `{
+  "ADDRESS": {
+    "CITY": "New York",
+    "ZIP": "10001"
+  },
+  "BOOKS": ["a", "b"],
+  "NAME": "John"
+}
+`,
+		},
+		{name: "yaml with separator",
+			args:  []string{"unflatten", "-separator", ".", "-o", "yaml"},
+			stdin: "db.host=localhost\ndb.port=5432\ndebug='true'\n",
+			want: `db:
+  host: "localhost"
+  port: "5432"
+debug: "true"
+`,
+		},
+		{name: "toml",
+			args:  []string{"unflatten", "-o", "toml"},
+			stdin: "db__host=localhost\nname=app\n",
+			want:  "name = \"app\"\n\n[db]\nhost = \"localhost\"\n",
+		},
+		{name: "invalid line",
+			args:    []string{"unflatten"},
+			stdin:   "NAME\n",
+			wantErr: true,
+		},
+		{name: "unknown format",
+			args:    []string{"unflatten", "-o", "xml"},
+			stdin:   "NAME=x\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.stdin), &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package merger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// KeyConflict is a parameter set by several keys, or several fields, in the
+// same configuration
+type KeyConflict struct {
+	// Key is the parameter name
+	Key string
+	// Keys are the keys of the source map, or the paths of the struct fields,
+	// that set the parameter
+	Keys []string
+}
+
+// KeyConflictError is the error returned when several keys set the same
+// parameter, or when several fields of a struct have the same parameter name
+type KeyConflictError struct {
+	Conflicts []KeyConflict
+}
+
+func (e *KeyConflictError) Error() string {
+	msgs := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, fmt.Sprintf("* %s: set by %s", c.Key, strings.Join(c.Keys, ", ")))
+	}
+	return fmt.Sprintf("%d key conflict(s):\n\n%s", len(e.Conflicts), strings.Join(msgs, "\n"))
+}
+
+// StrictKeys makes Merge and MergeMap return a KeyConflictError if a source
+// map has several keys for the same parameter, i.e. `address={"city":"LA"}`
+// and `address__city=SF`. Otherwise the more specific key wins
+func StrictKeys() Option {
+	return func(m *Merger) {
+		m.strictKeys = true
+	}
+}
+
+// KeyConflicts returns the keys of the source map that set the same
+// parameter, sorted by parameter. A key conflicts with another if they are
+// the same path, or if the path of the other key is inside it
+func KeyConflicts(srcMap map[string]string) []KeyConflict {
+	return defaultMerger.KeyConflicts(srcMap)
+}
+
+// KeyConflicts returns the keys of the source map that set the same
+// parameter, the keys are split with the KeyCodec of the Merger
+func (m *Merger) KeyConflicts(srcMap map[string]string) []KeyConflict {
+	keys := sortedBySpecificity(srcMap, m.keyCodec)
+	paths := make([][]string, len(keys))
+	for i, k := range keys {
+		paths[i] = m.keyCodec.path(k)
+	}
+
+	conflicts := []KeyConflict{}
+	inConflict := map[string]bool{}
+	for i, k := range keys {
+		if inConflict[k] {
+			continue
+		}
+		c := KeyConflict{Key: k, Keys: []string{k}}
+		for j := i + 1; j < len(keys); j++ {
+			if hasPathPrefix(paths[j], paths[i]) {
+				c.Keys = append(c.Keys, keys[j])
+				inConflict[keys[j]] = true
+			}
+		}
+		if len(c.Keys) > 1 {
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+	return conflicts
+}
+
+// checkKeys returns a KeyConflictError if any source map has conflicting keys
+func (m *Merger) checkKeys(srcMaps []map[string]string) error {
+	conflicts := []KeyConflict{}
+	for _, srcMap := range srcMaps {
+		conflicts = append(conflicts, m.KeyConflicts(srcMap)...)
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &KeyConflictError{Conflicts: conflicts}
+}
+
+// hasPathPrefix returns true if the path starts with the prefix
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckCollisions returns a KeyConflictError if several fields of the struct,
+// or pointer to a struct, have the same parameter name once they are in lower
+// case, i.e. the fields `Name` and `NAME`, or a field tagged `json:"db__host"`
+// and the field Host of a field DB. The fields are named like in
+// TransformToMap with the given tags, the json tag by default
+func CheckCollisions(v interface{}, tagNames ...string) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("invalid value, it's not a struct, it's a %T", v)
+	}
+
+	w := &fieldWalker{tagNames: tagNames, mapKey: SampleMapKey, mapKeyOnly: true}
+	fields := map[string][]string{}
+	for _, field := range w.walkFields(t, reflect.Value{}) {
+		fields[field.Name] = append(fields[field.Name], strings.Join(field.Path, "."))
+	}
+
+	conflicts := []KeyConflict{}
+	for name, paths := range fields {
+		if len(paths) > 1 {
+			conflicts = append(conflicts, KeyConflict{Key: name, Keys: paths})
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+	return &KeyConflictError{Conflicts: conflicts}
+}
@@ -0,0 +1,172 @@
+package merger_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func TestTransformMapSpecificity(t *testing.T) {
+	tests := []struct {
+		name   string
+		srcMap map[string]string
+		want   map[string]interface{}
+	}{
+		{name: "specific key wins over JSON",
+			srcMap: map[string]string{"address": `{"city":"LA","zip":"90001"}`, "address__city": "SF"},
+			want: map[string]interface{}{
+				"address": map[string]interface{}{"city": "SF", "zip": "90001"},
+			},
+		},
+		{name: "specific key wins over value",
+			srcMap: map[string]string{"address": "LA", "address__city": "SF"},
+			want: map[string]interface{}{
+				"address": map[string]interface{}{"city": "SF"},
+			},
+		},
+		{name: "specific JSON wins over JSON",
+			srcMap: map[string]string{"a": `{"b":{"c":1,"d":2}}`, "a__b": `{"c":3}`},
+			want: map[string]interface{}{
+				"a": map[string]interface{}{"b": map[string]interface{}{"c": float64(3), "d": float64(2)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The result is the same in every iteration order
+			for i := 0; i < 20; i++ {
+				if got := merger.TransformMap(tt.srcMap); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("TransformMap() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		codec  merger.KeyCodec
+		srcMap map[string]string
+		want   []merger.KeyConflict
+	}{
+		{name: "no conflicts",
+			codec:  merger.DefaultKeyCodec,
+			srcMap: map[string]string{"address__city": "SF", "address__zip": "94016", "addresses": "[a]"},
+			want:   []merger.KeyConflict{},
+		},
+		{name: "JSON and field",
+			codec:  merger.DefaultKeyCodec,
+			srcMap: map[string]string{"address": `{"city":"LA"}`, "address__city": "SF", "address__zip": "94016", "name": "John"},
+			want: []merger.KeyConflict{
+				{Key: "address", Keys: []string{"address", "address__city", "address__zip"}},
+			},
+		},
+		{name: "same path",
+			codec:  merger.KeyCodec{Separator: ".", Brackets: true},
+			srcMap: map[string]string{"grades.math": "9", "grades[math]": "8"},
+			want: []merger.KeyConflict{
+				{Key: "grades.math", Keys: []string{"grades.math", "grades[math]"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := merger.New(merger.WithKeyCodec(tt.codec)).KeyConflicts(tt.srcMap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyConflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger_StrictKeys(t *testing.T) {
+	type address struct {
+		City string
+	}
+	type config struct {
+		Address address
+	}
+	srcMap := map[string]string{"address": `{"city":"LA"}`, "address__city": "SF"}
+
+	got := config{}
+	if err := merger.New().MergeMap(&got, srcMap); err != nil {
+		t.Fatalf("MergeMap() error = %v", err)
+	}
+	if got.Address.City != "SF" {
+		t.Errorf("MergeMap() city = %q, want %q", got.Address.City, "SF")
+	}
+
+	err := merger.New(merger.StrictKeys()).MergeMap(&config{}, srcMap)
+	var conflictErr *merger.KeyConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("MergeMap() error = %v, want a KeyConflictError", err)
+	}
+	want := []merger.KeyConflict{{Key: "address", Keys: []string{"address", "address__city"}}}
+	if !reflect.DeepEqual(conflictErr.Conflicts, want) {
+		t.Errorf("MergeMap() conflicts = %v, want %v", conflictErr.Conflicts, want)
+	}
+}
+
+func TestCheckCollisions(t *testing.T) {
+	type db struct {
+		Host string
+	}
+	type ok struct {
+		Name string
+		DB   db
+		Tags map[string]db
+	}
+	type sameName struct {
+		Name  string
+		NAME  string `json:"NAME"`
+		Other string `json:"name"`
+	}
+	type nested struct {
+		DB     db
+		DBHost string `json:"db__host"`
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    []merger.KeyConflict
+		wantErr bool
+	}{
+		{name: "no collisions",
+			v: &ok{},
+		},
+		{name: "same name",
+			v: sameName{},
+			want: []merger.KeyConflict{
+				{Key: "name", Keys: []string{"Name", "NAME", "name"}},
+			},
+			wantErr: true,
+		},
+		{name: "nested",
+			v: &nested{},
+			want: []merger.KeyConflict{
+				{Key: "db__host", Keys: []string{"DB.Host", "db__host"}},
+			},
+			wantErr: true,
+		},
+		{name: "not a struct",
+			v:       "config",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := merger.CheckCollisions(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckCollisions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var conflictErr *merger.KeyConflictError
+			if errors.As(err, &conflictErr) && !reflect.DeepEqual(conflictErr.Conflicts, tt.want) {
+				t.Errorf("CheckCollisions() conflicts = %v, want %v", conflictErr.Conflicts, tt.want)
+			}
+		})
+	}
+}
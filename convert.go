@@ -0,0 +1,258 @@
+package merger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatOf returns the format of the file by its extension: .json, .yaml,
+// .yml, .toml, .env or .properties
+func FormatOf(filename string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".json":
+		return JSON, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".toml":
+		return TOML, nil
+	case ".env":
+		return Dotenv, nil
+	case ".properties":
+		return Properties, nil
+	default:
+		return "", fmt.Errorf("unknown format of the file %q", filename)
+	}
+}
+
+// Convert reads a configuration in the format from and returns it in the
+// format to. The configuration goes through the nested map used by Marshal,
+// so the keys of the dotenv and properties formats are nested with
+// FieldSeparator, like in TransformMap
+func Convert(r io.Reader, from, to Format) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Unmarshal(data, from)
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(m, to)
+}
+
+// Unmarshal returns the configuration in the given format as a nested map.
+// The parameters of the dotenv and properties formats are transformed with
+// TransformMap, the other formats keep the type of the values
+func Unmarshal(data []byte, f Format) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+
+	switch f {
+	case JSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("invalid JSON. %s", err)
+		}
+	case YAML:
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid YAML. %s", err)
+		}
+	case TOML:
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid TOML. %s", err)
+		}
+	case Dotenv:
+		vars, err := ParseDotenv(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return TransformMap(vars), nil
+	case Properties:
+		vars, err := ParseProperties(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return TransformMap(vars), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", f)
+	}
+
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	return normalize(m).(map[string]interface{}), nil
+}
+
+// normalize returns the value with the types used by TransformMap and
+// Marshal: the maps have string keys, the lists are []interface{} and the
+// times are strings
+func normalize(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalize(item)
+		}
+		return value
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[fmt.Sprintf("%v", k)] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+		return value
+	case []map[string]interface{}:
+		items := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			items = append(items, normalize(item))
+		}
+		return items
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return value
+	}
+}
+
+// ParseDotenv returns the variables defined in KEY=value lines, like in a
+// .env file. Empty lines and comments are ignored, the keys may start with
+// `export` and the values may be quoted
+func ParseDotenv(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid line %d %q, expected KEY=value", n, line)
+		}
+		key := strings.TrimSpace(strings.TrimPrefix(kv[0], "export "))
+		value := strings.TrimSpace(kv[1])
+
+		switch {
+		case len(value) > 1 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in line %d. %s", n, err)
+			}
+			value = unquoted
+		case len(value) > 1 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'"):
+			value = value[1 : len(value)-1]
+		}
+
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// ParseProperties returns the parameters of a Java properties file. The keys
+// are separated from the values by '=', ':' or a space, the lines starting
+// with '#' or '!' are comments and the lines ending with '\' continue in the
+// next line
+func ParseProperties(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	var logical string
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if len(logical) == 0 && (len(line) == 0 || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		// An odd number of backslashes at the end continues the line
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if trailing%2 == 1 {
+			logical += line[:len(line)-1]
+			continue
+		}
+		logical += line
+
+		key, value := splitProperty(logical)
+		logical = ""
+
+		k, err := propertiesUnescape(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key in line %d. %s", n, err)
+		}
+		v, err := propertiesUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in line %d. %s", n, err)
+		}
+		vars[k] = v
+	}
+	return vars, scanner.Err()
+}
+
+// splitProperty returns the key and value of the property line, still escaped
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if len(rest) != 0 && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return line[:i], rest
+		}
+	}
+	return line, ""
+}
+
+func propertiesUnescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+			sb.WriteRune(rune(code))
+			i += 4
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,129 @@
+package merger_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		from    merger.Format
+		to      merger.Format
+		want    string
+		wantErr bool
+	}{
+		{name: "json to yaml",
+			in:   `{"name": "John", "address": {"city": "Paris"}, "gpa": 3.5, "books": ["a", "b"]}`,
+			from: merger.JSON,
+			to:   merger.YAML,
+			want: "address:\n  city: \"Paris\"\nbooks:\n  - \"a\"\n  - \"b\"\ngpa: 3.5\nname: \"John\"\n",
+		},
+		{name: "yaml to dotenv",
+			in:   "name: John\naddress:\n  city: New York\nactive: true\n",
+			from: merger.YAML,
+			to:   merger.Dotenv,
+			want: "active=true\naddress__city=\"New York\"\nname=John\n",
+		},
+		{name: "toml to json",
+			in:   "name = \"John\"\n\n[[grades]]\nmath = 9\n\n[address]\ncity = \"Paris\"\n",
+			from: merger.TOML,
+			to:   merger.JSON,
+			want: "{\n  \"address\": {\n    \"city\": \"Paris\"\n  },\n  \"grades\": [{\"math\":9}],\n  \"name\": \"John\"\n}\n",
+		},
+		{name: "dotenv to toml",
+			in:   "NAME=John\nADDRESS__CITY=Paris\nBOOKS=[a, b]\n",
+			from: merger.Dotenv,
+			to:   merger.TOML,
+			want: "BOOKS = [\"a\", \"b\"]\nNAME = \"John\"\n\n[ADDRESS]\nCITY = \"Paris\"\n",
+		},
+		{name: "properties to properties",
+			in:   "# comment\nname = John \\\n  Doe\naddress__city: New York\nkey\\=with\\:colon=a\\tb\n",
+			from: merger.Properties,
+			to:   merger.Properties,
+			want: "address__city=New York\nkey%3Dwith\\:colon=a\\tb\nname=John Doe\n",
+		},
+		{name: "invalid json",
+			in:      `{"name"`,
+			from:    merger.JSON,
+			to:      merger.YAML,
+			wantErr: true,
+		},
+		{name: "unknown format",
+			in:      `{}`,
+			from:    "xml",
+			to:      merger.YAML,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.Convert(strings.NewReader(tt.in), tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Convert() = \n%q\n, want \n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	in := `{"name": "John", "address": {"city": "Paris"}, "books": ["a", "b"],
+		"grades": {"a__b": "1", "Computer Science": "2", "100%": "3", "x=y": "4"}}`
+	want, err := merger.Unmarshal([]byte(in), merger.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []merger.Format{merger.YAML, merger.TOML, merger.Dotenv, merger.Properties} {
+		t.Run(string(f), func(t *testing.T) {
+			out, err := merger.Convert(strings.NewReader(in), merger.JSON, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := merger.Convert(strings.NewReader(string(out)), f, merger.JSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := merger.Unmarshal(back, merger.JSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip through %s = %v, want %v", f, got, want)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     merger.Format
+		wantErr  bool
+	}{
+		{filename: "config.json", want: merger.JSON},
+		{filename: "config.YML", want: merger.YAML},
+		{filename: "/etc/app/config.toml", want: merger.TOML},
+		{filename: ".env", want: merger.Dotenv},
+		{filename: "app.properties", want: merger.Properties},
+		{filename: "config.ini", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got, err := merger.FormatOf(tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces the value of the secret parameters
+const RedactedValue = "*****"
+
+// ChangeKind is the kind of change of a parameter between two configurations
+type ChangeKind string
+
+// Kinds of changes
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is the difference of a parameter between two configurations. The
+// path is the parameter name as returned by TransformToMap
+type Change struct {
+	Path string     `json:"path"`
+	Old  string     `json:"old"`
+	New  string     `json:"new"`
+	Kind ChangeKind `json:"kind"`
+}
+
+// Diff returns the changes, sorted by path, to go from the configuration a to
+// the configuration b. The configurations are structs or pointers to structs,
+// any other value is considered an empty configuration. The values of the
+// fields tagged with `merger:"secret"`, or of type Secret, are redacted.
+func Diff(a, b interface{}) []Change {
+	return defaultMerger.Diff(a, b)
+}
+
+// Diff returns the changes, sorted by path, to go from the configuration a to
+// the configuration b. The secret values are redacted unless the Merger was
+// created with the Reveal option
+func (m *Merger) Diff(a, b interface{}) []Change {
+	fa := m.flattenAny(a)
+	fb := m.flattenAny(b)
+
+	changes := DiffMaps(fa.m, fb.m)
+	for i, c := range changes {
+		if m.reveal || (!fa.secrets[c.Path] && !fb.secrets[c.Path]) {
+			continue
+		}
+		if c.Kind != Added {
+			changes[i].Old = RedactedValue
+		}
+		if c.Kind != Removed {
+			changes[i].New = RedactedValue
+		}
+	}
+
+	return changes
+}
+
+// DiffMaps returns the changes, sorted by path, to go from the parameters a to
+// the parameters b, like the maps returned by TransformToMap
+func DiffMaps(a, b map[string]string) []Change {
+	changes := []Change{}
+	for path, newValue := range b {
+		oldValue, ok := a[path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: path, New: newValue, Kind: Added})
+		case oldValue != newValue:
+			changes = append(changes, Change{Path: path, Old: oldValue, New: newValue, Kind: Modified})
+		}
+	}
+	for path, oldValue := range a {
+		if _, ok := b[path]; !ok {
+			changes = append(changes, Change{Path: path, Old: oldValue, Kind: Removed})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// String returns the change in the format used by DiffText
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s = %s", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s = %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s = %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// DiffText renders the changes as text, one change per line
+func DiffText(changes []Change) string {
+	var sb strings.Builder
+	for _, c := range changes {
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// DiffJSON renders the changes as a JSON array
+func DiffJSON(changes []Change) ([]byte, error) {
+	if changes == nil {
+		changes = []Change{}
+	}
+	return json.MarshalIndent(changes, "", "  ")
+}
+
+// flattenAny returns the parameters of a struct or a pointer to a struct
+func (m *Merger) flattenAny(v interface{}) *flattener {
+	f := newFlattener([]string{defaultTagName})
+	f.codec = m.keyCodec
+	f.keyCase = m.keyCase
+	f.types = m.types
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() == reflect.Struct {
+		f.parseStruct(nil, val, false)
+	}
+
+	return f
+}
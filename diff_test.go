@@ -0,0 +1,144 @@
+package merger_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+type Credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password" merger:"secret"`
+}
+
+type Database struct {
+	Host        string      `json:"host"`
+	Credentials Credentials `json:"credentials"`
+	Tokens      []string    `json:"tokens" merger:"secret"`
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    interface{}
+		b    interface{}
+		want []merger.Change
+	}{
+		{name: "equal",
+			a:    &Simple{F1: 1, F2: "one"},
+			b:    Simple{F1: 1, F2: "one"},
+			want: []merger.Change{},
+		},
+		{name: "modified",
+			a: &Simple{F1: 1, F2: "one"},
+			b: &Simple{F1: 2, F2: "one"},
+			want: []merger.Change{
+				{Path: "f1", Old: "1", New: "2", Kind: merger.Modified},
+			},
+		},
+		{name: "added and removed",
+			a: &Person{Phones: map[string]Phone{"home": Phone{Number: "858-123-4567"}}},
+			b: &Person{Phones: map[string]Phone{"mobile": Phone{Number: "858-987-6543"}}},
+			want: []merger.Change{
+				{Path: "phones__home__available", Old: "false", Kind: merger.Removed},
+				{Path: "phones__home__number", Old: "858-123-4567", Kind: merger.Removed},
+				{Path: "phones__mobile__available", New: "false", Kind: merger.Added},
+				{Path: "phones__mobile__number", New: "858-987-6543", Kind: merger.Added},
+			},
+		},
+		{name: "from nothing",
+			a: nil,
+			b: &Simple{F1: 1},
+			want: []merger.Change{
+				{Path: "f1", New: "1", Kind: merger.Added},
+				{Path: "f2", New: "", Kind: merger.Added},
+			},
+		},
+		{name: "secrets",
+			a: &Database{
+				Host:        "localhost",
+				Credentials: Credentials{User: "admin", Password: "s3cr3t"},
+				Tokens:      []string{"t1"},
+			},
+			b: &Database{
+				Host:        "localhost",
+				Credentials: Credentials{User: "root", Password: "pa55w0rd"},
+				Tokens:      []string{"t1"},
+			},
+			want: []merger.Change{
+				{Path: "credentials__password", Old: merger.RedactedValue, New: merger.RedactedValue, Kind: merger.Modified},
+				{Path: "credentials__user", Old: "admin", New: "root", Kind: merger.Modified},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := merger.Diff(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMaps(t *testing.T) {
+	a := map[string]string{"f1": "1", "f3": "three", "f4": "same"}
+	b := map[string]string{"f1": "2", "f2": "two", "f4": "same"}
+	want := []merger.Change{
+		{Path: "f1", Old: "1", New: "2", Kind: merger.Modified},
+		{Path: "f2", New: "two", Kind: merger.Added},
+		{Path: "f3", Old: "three", Kind: merger.Removed},
+	}
+	if got := merger.DiffMaps(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffMaps() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	changes := []merger.Change{
+		{Path: "f1", Old: "1", New: "2", Kind: merger.Modified},
+		{Path: "f2", New: "two", Kind: merger.Added},
+		{Path: "f3", Old: "three", Kind: merger.Removed},
+	}
+	want := "~ f1 = 1 -> 2\n+ f2 = two\n- f3 = three\n"
+	if got := merger.DiffText(changes); got != want {
+		t.Errorf("DiffText() = %q, want %q", got, want)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []merger.Change
+		want    string
+	}{
+		{name: "nil",
+			changes: nil,
+			want:    `[]`,
+		},
+		{name: "modified",
+			changes: []merger.Change{
+				{Path: "f1", Old: "1", New: "2", Kind: merger.Modified},
+			},
+			want: `[
+  {
+    "path": "f1",
+    "old": "1",
+    "new": "2",
+    "kind": "modified"
+  }
+]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.DiffJSON(tt.changes)
+			if err != nil {
+				t.Fatalf("DiffJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DiffJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// DocFormat is the format of the documentation generated by Document
+type DocFormat string
+
+// Documentation formats
+const (
+	DocMarkdown DocFormat = "markdown"
+	DocText     DocFormat = "text"
+	DocJSON     DocFormat = "json"
+)
+
+// DocMapKey replaces the map keys in the documented environment variables
+const DocMapKey = "<KEY>"
+
+// DocOptions are the options to generate the documentation of the environment
+// variables of a configuration
+type DocOptions struct {
+	// Prefix is added to the name of every environment variable
+	Prefix string
+	// Format of the documentation, Markdown by default
+	Format DocFormat
+	// TagNames are the tags used to name the parameters, in order of importance,
+	// like in TransformToMap. By default it's the json tag
+	TagNames []string
+}
+
+// DocEntry is the documentation of an environment variable
+type DocEntry struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Required    bool   `json:"required"`
+	Secret      bool   `json:"secret"`
+	Description string `json:"description"`
+}
+
+// Document returns the documentation of the environment variables accepted by
+// the configuration v, a struct or a pointer to a struct. There is one
+// variable per parameter returned by TransformToMap, in upper case with the
+// given prefix, and the map keys replaced by DocMapKey. The default value is
+// taken from the `default` tag or, if there is no tag, from v. The description
+// is taken from the `description` tag and the variables are required or
+// secret when the fields are tagged with `merger:"required"` or
+// `merger:"secret"`. The secret values are redacted.
+//
+// To keep the documentation updated with go generate, write it from a main
+// package of your module, i.e. a file gendoc/main.go with:
+//
+//	func main() {
+//		b, err := merger.Document(&config.Config{}, merger.DocOptions{Prefix: "APP_"})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		if err := os.WriteFile("ENVIRONMENT.md", b, 0644); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// and the directive `//go:generate go run ./gendoc` in the package of the
+// configuration
+func Document(v interface{}, opts DocOptions) ([]byte, error) {
+	entries, err := DocumentEntries(v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Format {
+	case DocMarkdown, "":
+		return docMarkdown(entries), nil
+	case DocText:
+		return docText(entries)
+	case DocJSON:
+		return json.MarshalIndent(entries, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown documentation format %q", opts.Format)
+	}
+}
+
+// DocumentEntries returns the documentation of the environment variables
+// accepted by the configuration v, like Document
+func DocumentEntries(v interface{}, opts DocOptions) ([]DocEntry, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid value, it's not a struct, it's a %T", v)
+	}
+
+	w := &fieldWalker{tagNames: opts.TagNames, mapKey: DocMapKey, mapKeyOnly: true}
+	fields := w.walkFields(val.Type(), val)
+
+	entries := make([]DocEntry, 0, len(fields))
+	for _, field := range fields {
+		entry := DocEntry{
+			Name:        opts.Prefix + strings.ToUpper(field.Name),
+			Type:        field.Type.String(),
+			Default:     field.Default,
+			Required:    field.Required,
+			Secret:      field.Secret,
+			Description: field.Description,
+		}
+		if !field.HasDefault && field.Value.IsValid() && !field.Value.IsZero() {
+			entry.Default = valueString(field.Value)
+		}
+		if entry.Secret && len(entry.Default) != 0 {
+			entry.Default = RedactedValue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func docMarkdown(entries []DocEntry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("| Variable | Type | Default | Required | Secret | Description |\n")
+	buf.WriteString("|----------|------|---------|----------|--------|-------------|\n")
+	for _, e := range entries {
+		cells := []string{
+			"`" + e.Name + "`",
+			"`" + e.Type + "`",
+			markdownCode(e.Default),
+			yesNo(e.Required),
+			yesNo(e.Secret),
+			e.Description,
+		}
+		for i := range cells {
+			cells[i] = strings.Replace(cells[i], "|", `\|`, -1)
+		}
+		fmt.Fprintf(&buf, "| %s |\n", strings.Join(cells, " | "))
+	}
+	return buf.Bytes()
+}
+
+func docText(entries []DocEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VARIABLE\tTYPE\tDEFAULT\tREQUIRED\tSECRET\tDESCRIPTION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Name, e.Type, e.Default, yesNo(e.Required), yesNo(e.Secret), e.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func markdownCode(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
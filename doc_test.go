@@ -0,0 +1,126 @@
+package merger_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+type ServiceConfig struct {
+	Port     int              `json:"port" default:"8080" description:"Port to listen"`
+	Hosts    []string         `json:"hosts" description:"Allowed hosts"`
+	Token    merger.Secret    `json:"token" merger:"required" description:"API token"`
+	Database Database         `json:"database"`
+	Grades   map[string]Grade `json:"grades"`
+}
+
+func ExampleDocument() {
+	cfg := &ServiceConfig{
+		Hosts: []string{"localhost"},
+		Token: "s3cr3t",
+	}
+
+	doc, err := merger.Document(cfg, merger.DocOptions{Prefix: "APP_"})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(doc))
+	// Output:
+	// | Variable | Type | Default | Required | Secret | Description |
+	// |----------|------|---------|----------|--------|-------------|
+	// | `APP_PORT` | `int` | `8080` | no | no | Port to listen |
+	// | `APP_HOSTS` | `[]string` | `[localhost]` | no | no | Allowed hosts |
+	// | `APP_TOKEN` | `merger.Secret` | `*****` | yes | yes | API token |
+	// | `APP_DATABASE__HOST` | `string` |  | no | no |  |
+	// | `APP_DATABASE__CREDENTIALS__USER` | `string` |  | no | no |  |
+	// | `APP_DATABASE__CREDENTIALS__PASSWORD` | `string` |  | no | yes |  |
+	// | `APP_DATABASE__TOKENS` | `[]string` |  | no | yes |  |
+	// | `APP_GRADES__<KEY>__TEACHER` | `string` |  | no | no |  |
+	// | `APP_GRADES__<KEY>__NUMBER` | `float32` |  | no | no |  |
+}
+
+func TestDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		opts    merger.DocOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "text",
+			v:    &Simple{F1: 10},
+			opts: merger.DocOptions{Format: merger.DocText},
+			want: "VARIABLE  TYPE    DEFAULT  REQUIRED  SECRET  DESCRIPTION\n" +
+				"F1        int     10       no        no      \n" +
+				"F2        string           no        no      \n",
+		},
+		{name: "json",
+			v:    Simple{},
+			opts: merger.DocOptions{Format: merger.DocJSON, Prefix: "X_"},
+			want: `[
+  {
+    "name": "X_F1",
+    "type": "int",
+    "default": "",
+    "required": false,
+    "secret": false,
+    "description": ""
+  },
+  {
+    "name": "X_F2",
+    "type": "string",
+    "default": "",
+    "required": false,
+    "secret": false,
+    "description": ""
+  }
+]`,
+		},
+		{name: "unknown format",
+			v:       &Simple{},
+			opts:    merger.DocOptions{Format: "html"},
+			wantErr: true,
+		},
+		{name: "not a struct",
+			v:       10,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.Document(tt.v, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Document() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Document() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentEntries(t *testing.T) {
+	got, err := merger.DocumentEntries(&Movie{}, merger.DocOptions{})
+	if err != nil {
+		t.Fatalf("DocumentEntries() error = %v", err)
+	}
+	names := []string{}
+	for _, e := range got {
+		names = append(names, e.Name)
+	}
+	want := []string{
+		"TITLE",
+		"YEAR",
+		"ACTORS__<KEY>__FULL_NAME",
+		"ACTORS__<KEY>__AGE",
+		"GENRES",
+		"RELEASE_YEAR_PER_COUNTRY__<KEY>",
+	}
+	if !reflect.DeepEqual(names, want) {
+		b, _ := json.Marshal(names)
+		t.Errorf("DocumentEntries() names = %s, want %v", b, want)
+	}
+}
@@ -0,0 +1,158 @@
+package merger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// fieldInfo describes a parameter of a struct, a field that is not a struct
+type fieldInfo struct {
+	// Name is the parameter name, like the keys returned by TransformToMap
+	Name string
+	// Path are the names of the fields and map keys to the parameter
+	Path []string
+	// MapKeys tells which elements of Path are map keys
+	MapKeys     []bool
+	Type        reflect.Type
+	Value       reflect.Value
+	Default     string
+	HasDefault  bool
+	Description string
+	Required    bool
+	Secret      bool
+}
+
+// fieldWalker walks the fields of a struct type and, optionally, a value of
+// that type. The map entries of the value are walked but, if there are no
+// entries, the map is walked once with mapKey as the key, if it's set. With
+// mapKeyOnly the entries are never walked, only the mapKey
+type fieldWalker struct {
+	tagNames   []string
+	mapKey     string
+	mapKeyOnly bool
+	fields     []fieldInfo
+}
+
+// walkFields returns the parameters of the struct type t. If the value is valid it
+// has to be of type t
+func (w *fieldWalker) walkFields(t reflect.Type, v reflect.Value) []fieldInfo {
+	if len(w.tagNames) == 0 {
+		w.tagNames = []string{defaultTagName}
+	}
+	w.fields = []fieldInfo{}
+	w.walkStruct(fieldInfo{}, t, v, map[reflect.Type]bool{})
+	return w.fields
+}
+
+func (w *fieldWalker) walkStruct(parent fieldInfo, t reflect.Type, v reflect.Value, visiting map[reflect.Type]bool) {
+	// Recursive types are not walked twice
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, ignore := getName(field, w.tagNames)
+		if ignore {
+			continue
+		}
+
+		info := parent
+		info.Path = append(append([]string{}, parent.Path...), name)
+		info.MapKeys = append(append([]bool{}, parent.MapKeys...), false)
+		info.Name = info.flatName(LowerCase.apply)
+		info.Description = field.Tag.Get(descriptionTagName)
+		info.Default, info.HasDefault = field.Tag.Lookup(defaultValueTagName)
+		info.Required = hasOption(field, "required")
+		info.Secret = parent.Secret || isSecret(field)
+
+		var fieldValue reflect.Value
+		if v.IsValid() {
+			fieldValue = v.Field(i)
+		}
+		w.walk(info, field.Type, fieldValue, visiting)
+	}
+}
+
+func (w *fieldWalker) walk(info fieldInfo, t reflect.Type, v reflect.Value, visiting map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			v = v.Elem()
+		}
+	}
+	info.Secret = info.Secret || t == secretType
+
+	switch {
+	case DefaultTypeRegistry.isValueType(t):
+		// The registered types and the TextMarshaler are parameters
+	case t.Kind() == reflect.Struct:
+		w.walkStruct(info, t, v, visiting)
+		return
+	case t.Kind() == reflect.Map:
+		if w.mapKeyOnly || !v.IsValid() || v.Len() == 0 {
+			if len(w.mapKey) != 0 {
+				w.walkMapEntry(info, t, w.mapKey, reflect.Value{}, visiting)
+			}
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%v", keys[i].Interface()) < fmt.Sprintf("%v", keys[j].Interface())
+		})
+		for _, key := range keys {
+			w.walkMapEntry(info, t, fmt.Sprintf("%v", key.Interface()), v.MapIndex(key), visiting)
+		}
+		return
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		switch t.Elem().Kind() {
+		case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
+			// Like in TransformToMap, only a slice/array of simple type are parameters
+			return
+		}
+	}
+
+	info.Type = t
+	info.Value = v
+	w.fields = append(w.fields, info)
+}
+
+func (w *fieldWalker) walkMapEntry(info fieldInfo, t reflect.Type, key string, v reflect.Value, visiting map[reflect.Type]bool) {
+	// The default value and the required option are for the map, not for the entries
+	info.Default, info.HasDefault, info.Required = "", false, false
+	info.Path = append(append([]string{}, info.Path...), key)
+	info.MapKeys = append(append([]bool{}, info.MapKeys...), true)
+	info.Name = info.flatName(LowerCase.apply)
+	w.walk(info, t.Elem(), v, visiting)
+}
+
+// flatName returns the parameter name of the field like TransformToMap, with
+// DefaultKeyCodec: the field names are written with fieldCase and the map
+// keys keep their case and are escaped
+func (info fieldInfo) flatName(fieldCase func(string) string) string {
+	path := make([]keySegment, 0, len(info.Path))
+	for i, name := range info.Path {
+		if info.MapKeys[i] {
+			path = append(path, keySegment{name: DefaultKeyCodec.escapeMapKey(name), mapKey: true})
+			continue
+		}
+		path = append(path, keySegment{name: fieldCase(name)})
+	}
+	return DefaultKeyCodec.encode(path)
+}
+
+// valueString returns the value like TransformToMap does
+func valueString(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	f := newFlattener(nil)
+	f.appendTo(nil, v, false)
+	return f.m[""]
+}
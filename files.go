@@ -0,0 +1,127 @@
+package merger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IncludeKey is the key of the directive, in a configuration file, to include
+// other files. The value is a file, or a list of files, relative to the file
+// with the directive. The files included have lower priority than the file
+// including them
+const IncludeKey = "$include"
+
+// FromFiles returns the sources of the configuration files matching the given
+// patterns, like in filepath.Glob, with the highest priority first so they
+// can be merged with MergeSources. The files of every pattern are layered in
+// lexical order and every pattern overrides the previous ones, i.e.
+// FromFiles("config.yaml", "conf.d/*.yaml"). The format of every file is
+// defined by its extension and its decoded document is the Document of the
+// source, so the values keep their types. A pattern without wildcards has to
+// match an existing file
+func FromFiles(patterns ...string) ([]Source, error) {
+	l := &fileLoader{}
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q. %s", pattern, err)
+		}
+		if len(files) == 0 && !hasGlobMeta(pattern) {
+			return nil, fmt.Errorf("the file %q does not exist", pattern)
+		}
+		for _, file := range files {
+			if err := l.load(file, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The layers are loaded from the lowest to the highest priority
+	sources := make([]Source, 0, len(l.layers))
+	for i := range l.layers {
+		sources = append(sources, l.layers[len(l.layers)-i-1])
+	}
+	return sources, nil
+}
+
+// fileLoader loads the configuration files and the files they include as
+// layers, from the lowest to the highest priority
+type fileLoader struct {
+	layers []Source
+}
+
+// load loads the file after the files it includes. The including are the
+// files including this one, to detect the cycles
+func (l *fileLoader) load(file string, including []string) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	for i, f := range including {
+		if f == abs {
+			cycle := append(append([]string{}, including[i:]...), abs)
+			return fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	format, err := FormatOf(file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	doc, err := Unmarshal(data, format)
+	if err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+
+	includes, err := includedFiles(doc[IncludeKey])
+	if err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+	delete(doc, IncludeKey)
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(file), include)
+		}
+		if err := l.load(include, append(including, abs)); err != nil {
+			return err
+		}
+	}
+
+	l.layers = append(l.layers, Source{Name: file, Path: file, Document: doc})
+	return nil
+}
+
+// includedFiles returns the files of the include directive, a file or a list
+// of files
+func includedFiles(v interface{}) ([]string, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		files := make([]string, 0, len(value))
+		for _, item := range value {
+			file, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s, %v is not a file", IncludeKey, item)
+			}
+			files = append(files, file)
+		}
+		return files, nil
+	default:
+		return nil, fmt.Errorf("invalid %s, %v is not a file or a list of files", IncludeKey, v)
+	}
+}
+
+// hasGlobMeta returns true if the pattern has any of the special characters
+// of filepath.Match
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
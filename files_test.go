@@ -0,0 +1,184 @@
+package merger_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+type filesDB struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	CA   string `json:"ca" merger:"path"`
+}
+
+type filesConfig struct {
+	Name     string   `json:"name"`
+	Greeting string   `json:"greeting"`
+	Motto    string   `json:"motto"`
+	Debug    bool     `json:"debug"`
+	Tags     []string `json:"tags"`
+	DB       filesDB  `json:"db"`
+}
+
+// writeConfigFiles writes the files, the names are relative to a new
+// temporary directory that is returned
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFromFiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"config.yaml":          "name: app\ngreeting: \"Hello, world\"\nmotto: \"[x]\"\ntags: [a, b]\ndb:\n  host: localhost\n  port: 5432\n",
+		"conf.d/05-name.json":  `{"name": "app, v2"}`,
+		"conf.d/10-db.json":    `{"db": {"host": "db.local", "ca": "certs/ca.pem"}}`,
+		"conf.d/20-debug.toml": "debug = true\n",
+		"conf.d/30-port.env":   "db__port=6432\n",
+		"conf.d/README":        "not a configuration file",
+	})
+
+	sources, err := merger.FromFiles(filepath.Join(dir, "config.yaml"), filepath.Join(dir, "conf.d", "*.*"))
+	if err != nil {
+		t.Fatalf("FromFiles() error = %v", err)
+	}
+
+	names := []string{}
+	for _, src := range sources {
+		names = append(names, strings.TrimPrefix(src.Name, dir+string(filepath.Separator)))
+	}
+	wantNames := []string{
+		filepath.Join("conf.d", "30-port.env"),
+		filepath.Join("conf.d", "20-debug.toml"),
+		filepath.Join("conf.d", "10-db.json"),
+		filepath.Join("conf.d", "05-name.json"),
+		"config.yaml",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("FromFiles() sources = %v, want %v", names, wantNames)
+	}
+
+	got := filesConfig{}
+	if err := merger.MergeSources(&got, sources...); err != nil {
+		t.Fatalf("MergeSources() error = %v", err)
+	}
+	want := filesConfig{
+		Name:     "app, v2",
+		Greeting: "Hello, world",
+		Motto:    "[x]",
+		Debug:    true,
+		Tags:     []string{"a", "b"},
+		DB:       filesDB{Host: "db.local", Port: 6432, CA: filepath.Join(dir, "conf.d", "certs", "ca.pem")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeSources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromFilesInclude(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    filesConfig
+		wantErr string
+	}{
+		{name: "include",
+			files: map[string]string{
+				"config.yaml":      "$include: [base/db.yaml, base/name.json]\nname: app\n",
+				"base/db.yaml":     "$include: port.toml\ndb:\n  host: localhost\n  ca: ca.pem\n",
+				"base/port.toml":   "[db]\nport = 5432\nhost = \"ignored\"\n",
+				"base/name.json":   `{"name": "base", "debug": true}`,
+				"conf.d/debug.env": "debug=false\n",
+			},
+			want: filesConfig{
+				Name: "app",
+				DB:   filesDB{Host: "localhost", Port: 5432, CA: filepath.Join("base", "ca.pem")},
+			},
+		},
+		{name: "cycle",
+			files: map[string]string{
+				"config.yaml":  "$include: a.yaml\n",
+				"a.yaml":       "$include: b/b.yaml\n",
+				"b/b.yaml":     "$include: ../a.yaml\n",
+				"conf.d/x.env": "",
+			},
+			wantErr: "include cycle",
+		},
+		{name: "missing include",
+			files: map[string]string{
+				"config.yaml": "$include: missing.yaml\n",
+			},
+			wantErr: "missing.yaml",
+		},
+		{name: "invalid include",
+			files: map[string]string{
+				"config.yaml": "$include: {a: b}\n",
+			},
+			wantErr: "invalid $include",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			sources, err := merger.FromFiles(filepath.Join(dir, "config.yaml"), filepath.Join(dir, "conf.d", "*.env"))
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FromFiles() error = %v, want an error with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromFiles() error = %v", err)
+			}
+
+			got := filesConfig{}
+			if err := merger.MergeSources(&got, sources...); err != nil {
+				t.Fatalf("MergeSources() error = %v", err)
+			}
+			if len(got.DB.CA) != 0 {
+				got.DB.CA = strings.TrimPrefix(got.DB.CA, dir+string(filepath.Separator))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeSources() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFilesErrors(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"config.txt": "name=app\n",
+	})
+
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{name: "missing file", patterns: []string{filepath.Join(dir, "missing.yaml")}, wantErr: true},
+		{name: "glob without matches", patterns: []string{filepath.Join(dir, "conf.d", "*.yaml")}},
+		{name: "unknown format", patterns: []string{filepath.Join(dir, "config.txt")}, wantErr: true},
+		{name: "invalid pattern", patterns: []string{filepath.Join(dir, "[")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := merger.FromFiles(tt.patterns...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
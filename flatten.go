@@ -0,0 +1,161 @@
+package merger
+
+import (
+	"fmt"
+)
+
+// Flatten returns the parameters of the given interface (has to be a struct
+// pointer) like TransformToMap but the values keep their types: the numbers,
+// booleans, time.Time and slices of simple types are not converted to strings.
+// The values of the secret fields are redacted.
+func Flatten(v interface{}, tagNames ...string) (map[string]interface{}, error) {
+	return defaultMerger.Flatten(v, tagNames...)
+}
+
+// Flatten returns the parameters of the given interface (has to be a struct
+// pointer) with the values in their types. The parameters are named with the
+// KeyCodec and KeyCase of the Merger, and the values of the secret fields are
+// redacted unless the Merger was created with the Reveal option
+func (m *Merger) Flatten(v interface{}, tagNames ...string) (map[string]interface{}, error) {
+	f, err := m.parse(v, tagNames, true)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+
+	if m.reveal {
+		return f.values, nil
+	}
+	return redactValues(f.values, f.secrets), nil
+}
+
+// Unflatten returns the nested map of the parameters returned by Flatten. The
+// keys are split in fields by FieldSeparator and, unlike TransformMap, the
+// values are not parsed. Like in TransformMap, if several keys set the same
+// parameter the more specific key wins, i.e. with the keys `a` and `a__b` the
+// value of `a` is replaced by a map. Use UnflattenMap to get an error instead
+func Unflatten(flat map[string]interface{}) map[string]interface{} {
+	return defaultMerger.Unflatten(flat)
+}
+
+// Unflatten returns the nested map of the parameters returned by Flatten. The
+// keys are split in fields and map keys with the KeyCodec of the Merger
+func (m *Merger) Unflatten(flat map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, k := range sortedBySpecificity(flat, m.keyCodec) {
+		v := flat[k]
+		// The maps are copied, the more specific keys would modify them
+		if child, ok := v.(map[string]interface{}); ok {
+			v = copyMap(child)
+		}
+		result = transformToStructField(result, m.keyCodec.path(k), v)
+	}
+	return result
+}
+
+// FlattenMap returns the nested map, like a decoded JSON or YAML document, as
+// a map of parameters: the nested keys are joined with FieldSeparator and the
+// values are not modified. The empty maps are kept as values. It returns an
+// error if two different paths have the same parameter name, i.e. the keys
+// "a__b" and "a" with the key "b"
+func FlattenMap(m map[string]interface{}) (map[string]interface{}, error) {
+	return defaultMerger.FlattenMap(m)
+}
+
+// FlattenMap returns the nested map as a map of parameters named with the
+// KeyCodec of the Merger
+func (m *Merger) FlattenMap(nested map[string]interface{}) (map[string]interface{}, error) {
+	flat := map[string]interface{}{}
+	if err := m.flattenMapTo(flat, nil, nested); err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+func (m *Merger) flattenMapTo(flat map[string]interface{}, parent []keySegment, nested map[string]interface{}) error {
+	for _, k := range sortedKeys(nested) {
+		path := append(append([]keySegment{}, parent...), keySegment{name: k})
+		if child, ok := nested[k].(map[string]interface{}); ok && len(child) != 0 {
+			if err := m.flattenMapTo(flat, path, child); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := m.keyCodec.encode(path)
+		if _, ok := flat[name]; ok {
+			return fmt.Errorf("key collision, several keys are flattened to %q", name)
+		}
+		flat[name] = nested[k]
+	}
+	return nil
+}
+
+// UnflattenMap returns the map of parameters, like the one returned by
+// FlattenMap, as a nested map: the keys are split in fields by FieldSeparator
+// and the values are not modified. It returns an error if a key is a value and
+// also a map, i.e. the keys "a" and "a__b"
+func UnflattenMap(flat map[string]interface{}) (map[string]interface{}, error) {
+	return defaultMerger.UnflattenMap(flat)
+}
+
+// UnflattenMap returns the map of parameters as a nested map, the keys are
+// split with the KeyCodec of the Merger
+func (m *Merger) UnflattenMap(flat map[string]interface{}) (map[string]interface{}, error) {
+	nested := map[string]interface{}{}
+	for _, k := range sortedKeys(flat) {
+		path := m.keyCodec.decode(k)
+		if err := unflattenTo(nested, path, flat[k]); err != nil {
+			return nil, fmt.Errorf("key collision in %q: %s", k, err)
+		}
+	}
+	return nested, nil
+}
+
+func unflattenTo(nested map[string]interface{}, path []string, v interface{}) error {
+	k := path[0]
+	prev, exists := nested[k]
+	prevMap, prevIsMap := prev.(map[string]interface{})
+
+	if len(path) > 1 {
+		switch {
+		case !exists:
+			prevMap = map[string]interface{}{}
+			nested[k] = prevMap
+		case !prevIsMap:
+			return fmt.Errorf("%q is a value and a map", k)
+		}
+		return unflattenTo(prevMap, path[1:], v)
+	}
+
+	vMap, vIsMap := v.(map[string]interface{})
+	switch {
+	case !exists:
+		if vIsMap {
+			v = copyMap(vMap)
+		}
+		nested[k] = v
+		return nil
+	case prevIsMap && vIsMap:
+		for _, ck := range sortedKeys(vMap) {
+			if err := unflattenTo(prevMap, []string{ck}, vMap[ck]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%q is a value and a map", k)
+	}
+}
+
+// copyMap returns a deep copy of the nested maps, the other values are not
+// copied
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if child, ok := v.(map[string]interface{}); ok {
+			v = copyMap(child)
+		}
+		c[k] = v
+	}
+	return c
+}
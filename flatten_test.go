@@ -0,0 +1,235 @@
+package merger_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johandry/merger"
+)
+
+type flattenConfig struct {
+	Name     string             `json:"name"`
+	Port     int                `json:"port"`
+	Ratio    float64            `json:"ratio"`
+	Debug    bool               `json:"debug"`
+	Started  time.Time          `json:"started"`
+	Tags     []string           `json:"tags"`
+	Password string             `json:"password" merger:"secret"`
+	Limits   map[string]float32 `json:"limits"`
+}
+
+func TestFlatten(t *testing.T) {
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := &flattenConfig{
+		Name:     "api",
+		Port:     8080,
+		Ratio:    0.5,
+		Debug:    true,
+		Started:  started,
+		Tags:     []string{"a", "b"},
+		Password: "s3cr3t",
+		Limits:   map[string]float32{"cpu": 1.5},
+	}
+
+	tests := []struct {
+		name    string
+		merger  *merger.Merger
+		v       interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "redacted",
+			merger: merger.New(),
+			v:      v,
+			want: map[string]interface{}{
+				"name": "api", "port": 8080, "ratio": 0.5, "debug": true, "started": started,
+				"tags": []string{"a", "b"}, "password": merger.RedactedValue, "limits__cpu": float32(1.5),
+			},
+		},
+		{name: "revealed",
+			merger: merger.New(merger.Reveal()),
+			v:      v,
+			want: map[string]interface{}{
+				"name": "api", "port": 8080, "ratio": 0.5, "debug": true, "started": started,
+				"tags": []string{"a", "b"}, "password": "s3cr3t", "limits__cpu": float32(1.5),
+			},
+		},
+		{name: "nil",
+			merger: merger.New(),
+			v:      nil,
+			want:   map[string]interface{}{},
+		},
+		{name: "not a struct pointer",
+			merger:  merger.New(),
+			v:       *v,
+			want:    map[string]interface{}{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.merger.Flatten(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Flatten() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flatten() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	tests := []struct {
+		name string
+		flat map[string]interface{}
+		want map[string]interface{}
+	}{
+		{name: "nested",
+			flat: map[string]interface{}{"name": "api", "port": 8080, "limits__cpu": float32(1.5), "limits__mem": nil},
+			want: map[string]interface{}{
+				"name":   "api",
+				"port":   8080,
+				"limits": map[string]interface{}{"cpu": float32(1.5), "mem": nil},
+			},
+		},
+		{name: "escaped map keys",
+			flat: map[string]interface{}{"grades__Computer%20Science": []string{"a"}},
+			want: map[string]interface{}{
+				"grades": map[string]interface{}{"Computer Science": []string{"a"}},
+			},
+		},
+		{name: "more specific key wins",
+			flat: map[string]interface{}{"a": 1, "a__b": 2, "c__d": 3, "c": map[string]interface{}{"e": 4}},
+			want: map[string]interface{}{
+				"a": map[string]interface{}{"b": 2},
+				"c": map[string]interface{}{"d": 3, "e": 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The result can't depend on the order of the map iteration
+			for i := 0; i < 20; i++ {
+				if got := merger.Unflatten(tt.flat); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("Unflatten() = %#v, want %#v", got, tt.want)
+				}
+			}
+			for k, v := range tt.flat {
+				if child, ok := v.(map[string]interface{}); ok && len(child) != 1 {
+					t.Errorf("Unflatten() modified the value of %q = %v", k, v)
+				}
+			}
+		})
+	}
+}
+
+func TestFlattenMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   merger.KeyCodec
+		nested  map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "nested",
+			codec: merger.DefaultKeyCodec,
+			nested: map[string]interface{}{
+				"name":    "api",
+				"db":      map[string]interface{}{"host": "localhost", "port": 5432, "opts": map[string]interface{}{}},
+				"tags":    []interface{}{"a", map[string]interface{}{"b": 1}},
+				"nothing": nil,
+			},
+			want: map[string]interface{}{
+				"name": "api", "db__host": "localhost", "db__port": 5432, "db__opts": map[string]interface{}{},
+				"tags": []interface{}{"a", map[string]interface{}{"b": 1}}, "nothing": nil,
+			},
+		},
+		{name: "dot separator",
+			codec:  merger.KeyCodec{Separator: "."},
+			nested: map[string]interface{}{"db": map[string]interface{}{"host": "localhost"}},
+			want:   map[string]interface{}{"db.host": "localhost"},
+		},
+		{name: "escaped separator",
+			codec:  merger.KeyCodec{Separator: ".", Escape: `\`},
+			nested: map[string]interface{}{"db": map[string]interface{}{"host.name": "localhost"}, "db.host": "x"},
+			want:   map[string]interface{}{`db.host\.name`: "localhost", `db\.host`: "x"},
+		},
+		{name: "collision",
+			codec:   merger.DefaultKeyCodec,
+			nested:  map[string]interface{}{"db": map[string]interface{}{"host": "a"}, "db__host": "b"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.New(merger.WithKeyCodec(tt.codec)).FlattenMap(tt.nested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FlattenMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FlattenMap() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnflattenMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		flat    map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "nested",
+			flat: map[string]interface{}{"name": "api", "db__host": "localhost", "db__port": 5432, "tags": []interface{}{"a"}},
+			want: map[string]interface{}{
+				"name": "api",
+				"db":   map[string]interface{}{"host": "localhost", "port": 5432},
+				"tags": []interface{}{"a"},
+			},
+		},
+		{name: "map values are merged",
+			flat: map[string]interface{}{"db": map[string]interface{}{"host": "localhost"}, "db__port": 5432},
+			want: map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}},
+		},
+		{name: "value and map",
+			flat:    map[string]interface{}{"db": "localhost", "db__port": 5432},
+			wantErr: true,
+		},
+		{name: "map and value",
+			flat:    map[string]interface{}{"db": map[string]interface{}{"port": 1}, "db__port": 5432},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.UnflattenMap(tt.flat)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnflattenMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnflattenMap() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenMapRoundTrip(t *testing.T) {
+	nested := map[string]interface{}{
+		"name": "api",
+		"db":   map[string]interface{}{"host": "localhost", "opts": map[string]interface{}{}},
+	}
+	flat, err := merger.FlattenMap(nested)
+	if err != nil {
+		t.Fatalf("FlattenMap() error = %v", err)
+	}
+	got, err := merger.UnflattenMap(flat)
+	if err != nil {
+		t.Fatalf("UnflattenMap() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, nested) {
+		t.Errorf("round trip = %#v, want %#v", got, nested)
+	}
+}
@@ -0,0 +1,382 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is the format of a configuration file
+type Format string
+
+// Configuration file formats
+const (
+	JSON       Format = "json"
+	YAML       Format = "yaml"
+	TOML       Format = "toml"
+	Dotenv     Format = "env"
+	Properties Format = "properties"
+)
+
+// node is a parameter, or a group of parameters, of a configuration file. The
+// parameters keep the order they are added
+type node struct {
+	key      string
+	comment  string
+	value    interface{}
+	children []*node
+	object   bool
+}
+
+func newObjectNode(key string) *node {
+	return &node{key: key, object: true}
+}
+
+// child returns the object node with the given key, it's created if it does
+// not exists
+func (n *node) child(key string) *node {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	c := newObjectNode(key)
+	n.children = append(n.children, c)
+	return c
+}
+
+// set adds a parameter in the given path
+func (n *node) set(path []string, value interface{}, comment string) {
+	parent := n
+	for _, key := range path[:len(path)-1] {
+		parent = parent.child(key)
+	}
+	parent.children = append(parent.children, &node{key: path[len(path)-1], value: value, comment: comment})
+}
+
+// leaves returns the children that are not objects
+func (n *node) leaves() []*node {
+	leaves := []*node{}
+	for _, c := range n.children {
+		if !c.object {
+			leaves = append(leaves, c)
+		}
+	}
+	return leaves
+}
+
+// objects returns the children that are objects
+func (n *node) objects() []*node {
+	objects := []*node{}
+	for _, c := range n.children {
+		if c.object {
+			objects = append(objects, c)
+		}
+	}
+	return objects
+}
+
+// write writes the node in the given format. The parameters of the flat
+// formats, dotenv and properties, are named with the given function
+func (n *node) write(buf *bytes.Buffer, f Format, flatName func(path []string) string) error {
+	switch f {
+	case JSON:
+		writeJSON(buf, n, "")
+		buf.WriteString("\n")
+	case YAML:
+		writeYAML(buf, n, "")
+	case TOML:
+		writeTOML(buf, n, nil)
+	case Dotenv:
+		writeFlat(buf, n, nil, func(path []string, value interface{}) string {
+			return flatName(path) + "=" + dotenvValue(flatValue(value))
+		})
+	case Properties:
+		writeFlat(buf, n, nil, func(path []string, value interface{}) string {
+			return propertiesEscape(flatName(path), true) + "=" + propertiesEscape(flatValue(value), false)
+		})
+	default:
+		return fmt.Errorf("unknown format %q", f)
+	}
+	return nil
+}
+
+func writeComment(buf *bytes.Buffer, indent, comment string) {
+	if len(comment) == 0 {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(buf, "%s# %s\n", indent, line)
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, n *node, indent string) {
+	if !n.object {
+		buf.WriteString(jsonValue(n.value))
+		return
+	}
+	if len(n.children) == 0 {
+		buf.WriteString("{}")
+		return
+	}
+
+	buf.WriteString("{\n")
+	for i, c := range n.children {
+		fmt.Fprintf(buf, "%s  %s: ", indent, quote(c.key))
+		writeJSON(buf, c, indent+"  ")
+		if i < len(n.children)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent + "}")
+}
+
+func writeYAML(buf *bytes.Buffer, n *node, indent string) {
+	for _, c := range n.children {
+		writeComment(buf, indent, c.comment)
+		key := c.key
+		if !yamlPlainKey.MatchString(key) {
+			key = quote(key)
+		}
+
+		switch {
+		case c.object && len(c.children) == 0:
+			fmt.Fprintf(buf, "%s%s: {}\n", indent, key)
+		case c.object:
+			fmt.Fprintf(buf, "%s%s:\n", indent, key)
+			writeYAML(buf, c, indent+"  ")
+		default:
+			list, ok := c.value.([]interface{})
+			if !ok || len(list) == 0 {
+				fmt.Fprintf(buf, "%s%s: %s\n", indent, key, jsonValue(c.value))
+				continue
+			}
+			fmt.Fprintf(buf, "%s%s:\n", indent, key)
+			for _, item := range list {
+				fmt.Fprintf(buf, "%s  - %s\n", indent, jsonValue(item))
+			}
+		}
+	}
+}
+
+func writeTOML(buf *bytes.Buffer, n *node, path []string) {
+	for _, c := range n.leaves() {
+		writeComment(buf, "", c.comment)
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(c.key), tomlValue(c.value))
+	}
+
+	for _, c := range n.objects() {
+		childPath := append(append([]string{}, path...), tomlKey(c.key))
+		// The tables with only tables are defined implicitly
+		if len(c.leaves()) != 0 || len(c.children) == 0 {
+			if len(buf.Bytes()) != 0 {
+				buf.WriteString("\n")
+			}
+			writeComment(buf, "", c.comment)
+			fmt.Fprintf(buf, "[%s]\n", strings.Join(childPath, "."))
+		}
+		writeTOML(buf, c, childPath)
+	}
+}
+
+// writeFlat writes one line per parameter, created with the given function
+func writeFlat(buf *bytes.Buffer, n *node, path []string, line func(path []string, value interface{}) string) {
+	for _, c := range n.children {
+		childPath := append(append([]string{}, path...), c.key)
+		if c.object {
+			writeFlat(buf, c, childPath, line)
+			continue
+		}
+		writeComment(buf, "", c.comment)
+		buf.WriteString(line(childPath, c.value) + "\n")
+	}
+}
+
+var (
+	yamlPlainKey = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_ .-]*$`)
+	tomlBareKey  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	dotenvPlain  = regexp.MustCompile(`^[A-Za-z0-9_./:,@+\[\]-]*$`)
+)
+
+// quote returns the string as a JSON string, it's also a valid YAML and TOML
+// basic string
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func jsonValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quote(value)
+	case float32:
+		return formatFloat(float64(value))
+	case float64:
+		return formatFloat(value)
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, jsonValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		b, _ := json.Marshal(value)
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	return quote(key)
+}
+
+func tomlValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return `""`
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, tomlValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		items := make([]string, 0, len(value))
+		for _, k := range sortedKeys(value) {
+			items = append(items, tomlKey(k)+" = "+tomlValue(value[k]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return jsonValue(value)
+	}
+}
+
+// formatFloat returns the float always with a decimal point or exponent, so
+// it's not read as an integer
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEnN") {
+		s = s + ".0"
+	}
+	return s
+}
+
+// flatValue returns the value as a string like TransformToMap does
+func flatValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, flatValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		b, _ := json.Marshal(value)
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func dotenvValue(s string) string {
+	if dotenvPlain.MatchString(s) {
+		return s
+	}
+	return quote(s)
+}
+
+// propertiesEscape escapes the key or value of a properties file
+func propertiesEscape(s string, key bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case key && (r == '=' || r == ':' || r == ' ' || r == '#' || r == '!'):
+			sb.WriteString(`\` + string(r))
+		case !key && i == 0 && r == ' ':
+			sb.WriteString(`\` + string(r))
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Marshal returns the nested map, like the one returned by TransformMap, in
+// the given format. The keys are sorted and, in the dotenv and properties
+// formats, the nested keys are joined with FieldSeparator and escaped like in
+// TransformToMap, so they are read back by Unmarshal to the same keys
+func Marshal(m map[string]interface{}, f Format) ([]byte, error) {
+	return defaultMerger.Marshal(m, f)
+}
+
+// Marshal returns the nested map in the given format like Marshal, the nested
+// keys of the dotenv and properties formats are joined and escaped with the
+// KeyCodec of the Merger
+func (m *Merger) Marshal(nested map[string]interface{}, f Format) ([]byte, error) {
+	root := newObjectNode("")
+	addDocument(root, nested)
+
+	flatName := func(path []string) string {
+		return m.keyCodec.joinEscaped(path)
+	}
+
+	var buf bytes.Buffer
+	if err := root.write(&buf, f, flatName); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func addDocument(n *node, m map[string]interface{}) {
+	for _, k := range sortedKeys(m) {
+		switch value := m[k].(type) {
+		case map[string]interface{}:
+			addDocument(n.child(k), value)
+		case []string:
+			items := make([]interface{}, 0, len(value))
+			for _, item := range value {
+				items = append(items, item)
+			}
+			n.set([]string{k}, items, "")
+		default:
+			n.set([]string{k}, value, "")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package merger_test
+
+import (
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func TestMarshal(t *testing.T) {
+	m := merger.TransformMap(map[string]string{
+		"name":          "John",
+		"address__city": "New York",
+		"books":         "[a, b]",
+	})
+
+	tests := []struct {
+		name    string
+		format  merger.Format
+		want    string
+		wantErr bool
+	}{
+		{name: "json",
+			format: merger.JSON,
+			want:   "{\n  \"address\": {\n    \"city\": \"New York\"\n  },\n  \"books\": [\"a\", \"b\"],\n  \"name\": \"John\"\n}\n",
+		},
+		{name: "yaml",
+			format: merger.YAML,
+			want:   "address:\n  city: \"New York\"\nbooks:\n  - \"a\"\n  - \"b\"\nname: \"John\"\n",
+		},
+		{name: "toml",
+			format: merger.TOML,
+			want:   "books = [\"a\", \"b\"]\nname = \"John\"\n\n[address]\ncity = \"New York\"\n",
+		},
+		{name: "dotenv",
+			format: merger.Dotenv,
+			want:   "address__city=\"New York\"\nbooks=\"[a, b]\"\nname=John\n",
+		},
+		{name: "unknown",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.Marshal(m, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = \n%q\n, want \n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger_Marshal(t *testing.T) {
+	m := merger.New(merger.WithKeyCodec(merger.KeyCodec{Separator: "."}))
+	nested := m.TransformMap(map[string]string{"name": "John", "address.city": "LA"})
+
+	got, err := m.Marshal(nested, merger.Properties)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := "address.city=LA\nname=John\n"; string(got) != want {
+		t.Errorf("Marshal() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,12 @@
 module github.com/johandry/merger
 
+go 1.18
+
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/imdario/mergo v0.3.6
 	github.com/mitchellh/mapstructure v1.1.2
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -0,0 +1,162 @@
+package merger
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyCase is how the field names are written in the parameter names returned
+// by TransformToMap. The map keys are never modified
+type KeyCase string
+
+// Key cases
+const (
+	// LowerCase writes the field names in lower case, i.e. textbooks. It's the
+	// default
+	LowerCase KeyCase = "lower"
+	// PreserveCase writes the field names as they are, i.e. TextBooks
+	PreserveCase KeyCase = "preserve"
+	// UpperSnakeCase writes the field names in upper case with the words
+	// separated by '_', like environment variables, i.e. TEXT_BOOKS
+	UpperSnakeCase KeyCase = "UPPER_SNAKE"
+	// KebabCase writes the field names in lower case with the words separated
+	// by '-', like command line flags, i.e. text-books
+	KebabCase KeyCase = "kebab"
+)
+
+// WithKeyCase sets how the field names are written by TransformToMap. Any
+// case is accepted by MergeMap, the parameter names match the struct fields
+// ignoring the case and the '_' and '-' characters, but the map keys are case
+// sensitive
+func WithKeyCase(c KeyCase) Option {
+	return func(m *Merger) {
+		m.keyCase = c
+	}
+}
+
+// apply returns the field name in the key case
+func (c KeyCase) apply(name string) string {
+	switch c {
+	case PreserveCase:
+		return name
+	case UpperSnakeCase:
+		return strings.ToUpper(strings.Join(words(name), "_"))
+	case KebabCase:
+		return strings.ToLower(strings.Join(words(name), "-"))
+	default:
+		return strings.ToLower(name)
+	}
+}
+
+// words returns the words of a name in camel case, snake case or kebab case
+func words(name string) []string {
+	ws := []string{}
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' || runes[i] == '-' || unicode.IsSpace(runes[i]) {
+			if i > start {
+				ws = append(ws, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		// A new word starts at an upper case letter after a lower case letter or
+		// digit, or at the last upper case letter of an acronym, i.e. HTTPServer
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+			ws = append(ws, string(runes[start:i]))
+			start = i
+		}
+	}
+	return ws
+}
+
+// fieldKey returns the name normalized to match a struct field in any key
+// case: lower case without '_' nor '-'
+func fieldKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// fieldTagName returns the name of the field for the given tag, or for the
+// mapstructure tag if it's empty. It's the field name if there is no tag
+func fieldTagName(field reflect.StructField, tagName string) string {
+	if len(tagName) == 0 {
+		tagName = "mapstructure"
+	}
+	name := strings.SplitN(field.Tag.Get(tagName), ",", 2)[0]
+	if len(name) == 0 {
+		name = field.Name
+	}
+	return name
+}
+
+// normalizeKeys renames the keys of the nested map that match a field of the
+// type t to the field name used to decode it, so the fields are found in any
+// key case. The map keys are not renamed
+func normalizeKeys(t reflect.Type, m map[string]interface{}, tagName string) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		for k, v := range m {
+			if child, ok := v.(map[string]interface{}); ok {
+				m[k] = normalizeKeys(t.Elem(), child, tagName)
+			}
+		}
+		return m
+	case reflect.Struct:
+	default:
+		return m
+	}
+
+	// The keys are sorted so, if several keys match the same field, the
+	// result is always the same. The maps are merged and, for any other value,
+	// the key equal to the field name wins
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]interface{}, len(m))
+	for _, k := range keys {
+		field, ok := structFieldByKey(t, k, tagName)
+		if !ok {
+			result[k] = m[k]
+			continue
+		}
+		name := fieldTagName(field, tagName)
+
+		v := m[k]
+		if child, ok := v.(map[string]interface{}); ok {
+			v = normalizeKeys(field.Type, child, tagName)
+		}
+
+		prev, exists := result[name]
+		prevMap, prevIsMap := prev.(map[string]interface{})
+		vMap, vIsMap := v.(map[string]interface{})
+		switch {
+		case !exists:
+			result[name] = v
+		case prevIsMap && vIsMap:
+			result[name] = mergeTwoMaps(prevMap, vMap, k == name)
+		case k == name:
+			result[name] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package merger_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+type keyCaseConfig struct {
+	Name      string                 `json:"name"`
+	TextBooks []string               `json:"TextBooks"`
+	HTTPPort  int                    `json:"HTTPPort"`
+	Grades    map[string]keyCaseNote `json:"Grades"`
+}
+
+type keyCaseNote struct {
+	Number int `json:"Number"`
+}
+
+func TestMerger_TransformToMapKeyCase(t *testing.T) {
+	v := &keyCaseConfig{
+		Name:      "John",
+		TextBooks: []string{"a"},
+		HTTPPort:  80,
+		Grades:    map[string]keyCaseNote{"Science": {Number: 90}},
+	}
+
+	tests := []struct {
+		name    string
+		keyCase merger.KeyCase
+		want    map[string]string
+	}{
+		{name: "lower",
+			keyCase: merger.LowerCase,
+			want:    map[string]string{"name": "John", "textbooks": "[a]", "httpport": "80", "grades__Science__number": "90"},
+		},
+		{name: "preserve",
+			keyCase: merger.PreserveCase,
+			want:    map[string]string{"name": "John", "TextBooks": "[a]", "HTTPPort": "80", "Grades__Science__Number": "90"},
+		},
+		{name: "upper snake",
+			keyCase: merger.UpperSnakeCase,
+			want:    map[string]string{"NAME": "John", "TEXT_BOOKS": "[a]", "HTTP_PORT": "80", "GRADES__Science__NUMBER": "90"},
+		},
+		{name: "kebab",
+			keyCase: merger.KebabCase,
+			want:    map[string]string{"name": "John", "text-books": "[a]", "http-port": "80", "grades__Science__number": "90"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.New(merger.WithKeyCase(tt.keyCase)).TransformToMap(v)
+			if err != nil {
+				t.Fatalf("TransformToMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransformToMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger_MergeMapKeyCase(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyCase merger.KeyCase
+		srcMap  map[string]string
+		want    keyCaseConfig
+	}{
+		{name: "lower",
+			keyCase: merger.LowerCase,
+			srcMap:  map[string]string{"name": "John", "textbooks": "[a]", "httpport": "80", "grades__Science__number": "90"},
+			want:    keyCaseConfig{Name: "John", TextBooks: []string{"a"}, HTTPPort: 80, Grades: map[string]keyCaseNote{"Science": {Number: 90}}},
+		},
+		{name: "upper snake",
+			keyCase: merger.UpperSnakeCase,
+			srcMap:  map[string]string{"NAME": "John", "TEXT_BOOKS": "[a]", "HTTP_PORT": "80", "GRADES__Science__NUMBER": "90"},
+			want:    keyCaseConfig{Name: "John", TextBooks: []string{"a"}, HTTPPort: 80, Grades: map[string]keyCaseNote{"Science": {Number: 90}}},
+		},
+		{name: "kebab",
+			keyCase: merger.KebabCase,
+			srcMap:  map[string]string{"name": "John", "text-books": "[a]", "http-port": "80", "grades__Science__number": "90"},
+			want:    keyCaseConfig{Name: "John", TextBooks: []string{"a"}, HTTPPort: 80, Grades: map[string]keyCaseNote{"Science": {Number: 90}}},
+		},
+		{name: "map keys are case sensitive",
+			keyCase: merger.LowerCase,
+			srcMap:  map[string]string{"grades__science__number": "90"},
+			want:    keyCaseConfig{Grades: map[string]keyCaseNote{"science": {Number: 90}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keyCaseConfig{}
+			if err := merger.New(merger.WithKeyCase(tt.keyCase)).MergeMap(&got, tt.srcMap); err != nil {
+				t.Fatalf("MergeMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeMap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package merger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyCodec defines the syntax of the parameter names, the path of field names
+// and map keys to a value. It's used to transform the names into paths, in
+// TransformMap, and the paths into names, in TransformToMap
+type KeyCodec struct {
+	// Separator separates the field names and map keys, FieldSeparator if it's
+	// empty. It can have one or more characters
+	Separator string
+	// Escape, if set, is the sequence to escape the separator, the escape
+	// itself and the brackets inside a field name or map key. I.e. with the
+	// escape `\`, the name `a\.b.c` is the path "a.b", "c"
+	Escape string
+	// Brackets enables the syntax `name[key]` for the map keys, i.e.
+	// `grades[Computer Science].number`. The map keys inside brackets can have
+	// the separator and spaces
+	Brackets bool
+}
+
+// DefaultKeyCodec is the KeyCodec used by default, the names are separated by
+// FieldSeparator without escaping nor brackets
+var DefaultKeyCodec = KeyCodec{Separator: FieldSeparator}
+
+// WithKeyCodec sets the syntax of the parameter names used by the Merger
+func WithKeyCodec(c KeyCodec) Option {
+	return func(m *Merger) {
+		if len(c.Separator) == 0 {
+			c.Separator = FieldSeparator
+		}
+		m.keyCodec = c
+	}
+}
+
+// keySegment is a field name or map key in the path of a parameter
+type keySegment struct {
+	name   string
+	mapKey bool
+}
+
+// encode returns the parameter name of the path
+func (c KeyCodec) encode(path []keySegment) string {
+	var sb strings.Builder
+	for i, seg := range path {
+		if c.Brackets && seg.mapKey && i > 0 {
+			sb.WriteString("[" + c.escape(seg.name, true) + "]")
+			continue
+		}
+		if i > 0 {
+			sb.WriteString(c.Separator)
+		}
+		sb.WriteString(c.escape(seg.name, false))
+	}
+	return sb.String()
+}
+
+// join returns the parameter name of the path of a nested map, where the
+// field names can't be told apart from the map keys
+func (c KeyCodec) join(path []string) string {
+	segments := make([]keySegment, 0, len(path))
+	for _, name := range path {
+		segments = append(segments, keySegment{name: name})
+	}
+	return c.encode(segments)
+}
+
+// joinEscaped returns the parameter name of the path of a nested map like
+// join, but every field name or map key is escaped like the map keys so the
+// name is decoded back to the same path. With brackets, the segments with the
+// separator or a bracket are written inside brackets
+func (c KeyCodec) joinEscaped(path []string) string {
+	segments := make([]keySegment, 0, len(path))
+	for i, name := range path {
+		mapKey := c.Brackets && i > 0 && (strings.Contains(name, c.Separator) || strings.Contains(name, "["))
+		segments = append(segments, keySegment{name: c.escapeMapKey(name), mapKey: mapKey})
+	}
+	return c.encode(segments)
+}
+
+// escape escapes the separator, the escape sequence and the brackets in the
+// name. Inside brackets only the closing bracket and the escape sequence are
+// escaped
+func (c KeyCodec) escape(name string, inBrackets bool) string {
+	if len(c.Escape) == 0 {
+		return name
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(name); {
+		rest := name[i:]
+		switch {
+		case strings.HasPrefix(rest, c.Escape):
+			sb.WriteString(c.Escape + c.Escape)
+			i += len(c.Escape)
+		case !inBrackets && strings.HasPrefix(rest, c.Separator):
+			sb.WriteString(c.Escape + c.Separator)
+			i += len(c.Separator)
+		case c.Brackets && (rest[0] == ']' || (!inBrackets && rest[0] == '[')):
+			sb.WriteString(c.Escape + rest[:1])
+			i++
+		default:
+			sb.WriteByte(rest[0])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// decode returns the path of the parameter name. Without escape nor
+// brackets, it's the name split by the separator. A bracket that is not
+// closed is part of the name
+func (c KeyCodec) decode(key string) []string {
+	path := []string{}
+	var current strings.Builder
+	// afterBracket is true if the last segment was a map key in brackets, then
+	// the following separator doesn't start a new segment
+	afterBracket := false
+
+	for i := 0; i < len(key); {
+		rest := key[i:]
+		switch {
+		case len(c.Escape) != 0 && strings.HasPrefix(rest, c.Escape) && len(rest) > len(c.Escape):
+			i += len(c.Escape)
+			n := c.escaped(key[i:])
+			current.WriteString(key[i : i+n])
+			i += n
+			afterBracket = false
+		case strings.HasPrefix(rest, c.Separator):
+			if !afterBracket || current.Len() != 0 {
+				path = append(path, current.String())
+			}
+			current.Reset()
+			afterBracket = false
+			i += len(c.Separator)
+		case c.Brackets && rest[0] == '[':
+			name, n, ok := c.bracketKey(rest)
+			if !ok {
+				current.WriteByte('[')
+				i++
+				afterBracket = false
+				continue
+			}
+			if current.Len() != 0 {
+				path = append(path, current.String())
+			}
+			path = append(path, name)
+			current.Reset()
+			afterBracket = true
+			i += n
+		default:
+			current.WriteByte(rest[0])
+			afterBracket = false
+			i++
+		}
+	}
+	if !afterBracket || current.Len() != 0 {
+		path = append(path, current.String())
+	}
+
+	return path
+}
+
+// escaped returns the length of the escaped sequence at the beginning of s:
+// the separator, the escape sequence or a character
+func (c KeyCodec) escaped(s string) int {
+	switch {
+	case strings.HasPrefix(s, c.Separator):
+		return len(c.Separator)
+	case strings.HasPrefix(s, c.Escape):
+		return len(c.Escape)
+	default:
+		return 1
+	}
+}
+
+// bracketKey returns the map key inside the brackets at the beginning of s
+// and the length of the brackets with the key
+func (c KeyCodec) bracketKey(s string) (string, int, bool) {
+	var sb strings.Builder
+	for i := 1; i < len(s); {
+		rest := s[i:]
+		switch {
+		case len(c.Escape) != 0 && strings.HasPrefix(rest, c.Escape) && len(rest) > len(c.Escape):
+			i += len(c.Escape)
+			n := c.escaped(s[i:])
+			sb.WriteString(s[i : i+n])
+			i += n
+		case rest[0] == ']':
+			return sb.String(), i + 1, true
+		default:
+			sb.WriteByte(rest[0])
+			i++
+		}
+	}
+	return "", 0, false
+}
+
+// path returns the field names and map keys of the parameter name, without
+// the percent encoding of the map keys
+func (c KeyCodec) path(key string) []string {
+	path := c.decode(key)
+	for i := range path {
+		path[i] = c.unescapeMapKey(path[i])
+	}
+	return path
+}
+
+// escapeMapKey returns the map key so it can be decoded back exactly. If the
+// KeyCodec has an escape sequence the key is escaped when it's encoded,
+// otherwise the characters that can't be in a key are percent-encoded: '%',
+// '=', the spaces and control characters, and the separator characters that
+// would split the key
+func (c KeyCodec) escapeMapKey(key string) string {
+	if len(c.Escape) != 0 {
+		return key
+	}
+
+	runes := []rune(key)
+	isSep := func(i int) bool {
+		return i >= 0 && i < len(runes) && strings.ContainsRune(c.Separator, runes[i])
+	}
+
+	var sb strings.Builder
+	for i, r := range runes {
+		encode := r == '%' || r == '=' || unicode.IsSpace(r) || unicode.IsControl(r)
+		switch {
+		case c.Brackets:
+			encode = r == '%' || r == ']' || unicode.IsControl(r)
+		case isSep(i):
+			// A separator character is encoded if, with the characters around, it
+			// could be part of a separator
+			encode = utf8.RuneCountInString(c.Separator) == 1 || i == 0 || i == len(runes)-1 || isSep(i-1) || isSep(i+1)
+		}
+		if !encode {
+			sb.WriteRune(r)
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		for _, b := range buf[:utf8.EncodeRune(buf[:], r)] {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+// unescapeMapKey returns the field name or map key without the percent
+// encoding of escapeMapKey. The invalid percent-encoded sequences are kept
+func (c KeyCodec) unescapeMapKey(key string) string {
+	if len(c.Escape) != 0 || !strings.Contains(key, "%") {
+		return key
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == '%' && i+2 < len(key) {
+			if b, err := strconv.ParseUint(key[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(b))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(key[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package merger_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func TestMerger_TransformMapKeyCodec(t *testing.T) {
+	tests := []struct {
+		name   string
+		codec  merger.KeyCodec
+		srcMap map[string]string
+		want   map[string]interface{}
+	}{
+		{name: "default",
+			codec:  merger.KeyCodec{},
+			srcMap: map[string]string{"address__city": "Paris", "name": "John"},
+			want: map[string]interface{}{
+				"address": map[string]interface{}{"city": "Paris"},
+				"name":    "John",
+			},
+		},
+		{name: "dot separator",
+			codec:  merger.KeyCodec{Separator: "."},
+			srcMap: map[string]string{"address.city": "Paris", "text_books": "[a]"},
+			want: map[string]interface{}{
+				"address":    map[string]interface{}{"city": "Paris"},
+				"text_books": []string{"a"},
+			},
+		},
+		{name: "single char separator",
+			codec:  merger.KeyCodec{Separator: "_"},
+			srcMap: map[string]string{"address_city": "Paris"},
+			want: map[string]interface{}{
+				"address": map[string]interface{}{"city": "Paris"},
+			},
+		},
+		{name: "escape",
+			codec:  merger.KeyCodec{Separator: ".", Escape: `\`},
+			srcMap: map[string]string{`grades.v1\.0.teacher`: "Bob", `grades.a\\b.teacher`: "Ann"},
+			want: map[string]interface{}{
+				"grades": map[string]interface{}{
+					"v1.0": map[string]interface{}{"teacher": "Bob"},
+					`a\b`:  map[string]interface{}{"teacher": "Ann"},
+				},
+			},
+		},
+		{name: "multi char escape",
+			codec:  merger.KeyCodec{Escape: "~~"},
+			srcMap: map[string]string{"grades__a~~__b__number": "10"},
+			want: map[string]interface{}{
+				"grades": map[string]interface{}{
+					"a__b": map[string]interface{}{"number": "10"},
+				},
+			},
+		},
+		{name: "brackets",
+			codec: merger.KeyCodec{Separator: ".", Brackets: true},
+			srcMap: map[string]string{
+				"grades[Computer Science].number": "10",
+				"grades[v1.0][x]":                 "y",
+				"grades[open":                     "z",
+			},
+			want: map[string]interface{}{
+				"grades": map[string]interface{}{
+					"Computer Science": map[string]interface{}{"number": "10"},
+					"v1.0":             map[string]interface{}{"x": "y"},
+				},
+				"grades[open": "z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := merger.New(merger.WithKeyCodec(tt.codec))
+			if got := m.TransformMap(tt.srcMap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransformMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger_TransformToMapKeyCodec(t *testing.T) {
+	student := &Student{
+		Name:    "John",
+		Address: Address{City: "Paris"},
+		Grades: map[string]Grade{
+			"Computer Science": {Teacher: "Bob", Number: 10},
+			"v1.0":             {Teacher: "Ann", Number: 9},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		codec merger.KeyCodec
+		want  map[string]string
+	}{
+		{name: "dot separator",
+			codec: merger.KeyCodec{Separator: "."},
+			want: map[string]string{
+				"name": "John", "text_books": "[]", "gpa": "0", "address.city": "Paris", "address.country": "",
+				"grades.Computer%20Science.teacher": "Bob", "grades.Computer%20Science.number": "10",
+				"grades.v1%2E0.teacher": "Ann", "grades.v1%2E0.number": "9",
+			},
+		},
+		{name: "escape",
+			codec: merger.KeyCodec{Separator: ".", Escape: `\`},
+			want: map[string]string{
+				"name": "John", "text_books": "[]", "gpa": "0", "address.city": "Paris", "address.country": "",
+				"grades.Computer Science.teacher": "Bob", "grades.Computer Science.number": "10",
+				`grades.v1\.0.teacher`: "Ann", `grades.v1\.0.number`: "9",
+			},
+		},
+		{name: "brackets",
+			codec: merger.KeyCodec{Separator: ".", Brackets: true},
+			want: map[string]string{
+				"name": "John", "text_books": "[]", "gpa": "0", "address.city": "Paris", "address.country": "",
+				"grades[Computer Science].teacher": "Bob", "grades[Computer Science].number": "10",
+				"grades[v1.0].teacher": "Ann", "grades[v1.0].number": "9",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.New(merger.WithKeyCodec(tt.codec)).TransformToMap(student)
+			if err != nil {
+				t.Fatalf("TransformToMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransformToMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerger_MergeMapKeyCodec(t *testing.T) {
+	m := merger.New(merger.WithKeyCodec(merger.KeyCodec{Separator: ".", Escape: `\`, Brackets: true}))
+
+	var got Student
+	err := m.MergeMap(&got, map[string]string{
+		"address.city":                    "Paris",
+		"grades[Computer Science].number": "10",
+		`grades[a\]b].teacher`:            "Bob",
+	})
+	if err != nil {
+		t.Fatalf("MergeMap() error = %v", err)
+	}
+
+	want := Student{
+		Address: Address{City: "Paris"},
+		Grades: map[string]Grade{
+			"Computer Science": {Number: 10},
+			"a]b":              {Teacher: "Bob"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeMap() = %+v, want %+v", got, want)
+	}
+}
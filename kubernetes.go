@@ -0,0 +1,167 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// KubernetesOptions are the options to generate the Kubernetes manifests of a
+// configuration
+type KubernetesOptions struct {
+	// Name of the ConfigMap and the Secret, it's required
+	Name string
+	// Namespace of the ConfigMap and the Secret, if any
+	Namespace string
+	// Labels of the ConfigMap and the Secret
+	Labels map[string]string
+	// Prefix is added to the name of every environment variable
+	Prefix string
+	// TagNames are the tags used to name the parameters, in order of importance,
+	// like in TransformToMap. By default it's the json tag
+	TagNames []string
+}
+
+// kubernetesKey are the valid keys of the ConfigMap and Secret data
+var kubernetesKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// KubernetesManifests returns the ConfigMap and the Secret manifests, in a
+// YAML document, of the configuration v
+func KubernetesManifests(v interface{}, opts KubernetesOptions) ([]byte, error) {
+	cm, err := KubernetesConfigMap(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := KubernetesSecret(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(cm, "---\n"...), secret...), nil
+}
+
+// KubernetesConfigMap returns the ConfigMap manifest, in YAML, with the
+// parameters of the configuration v that are not secret. The configuration is
+// a struct or a pointer to a struct and the keys are the environment variables
+// returned by TransformToMap, in upper case with the given prefix. The map
+// keys are upper cased too, so they are read back in upper case, and the map
+// keys that can't be part of a data key, i.e. with spaces or FieldSeparator,
+// return an error
+func KubernetesConfigMap(v interface{}, opts KubernetesOptions) ([]byte, error) {
+	data, _, err := kubernetesData(v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeKubernetesManifest(&buf, "ConfigMap", opts)
+	writeKubernetesData(&buf, data)
+	return buf.Bytes(), nil
+}
+
+// KubernetesSecret returns the Secret manifest, in YAML, with the secret
+// parameters of the configuration v, the fields tagged with `merger:"secret"`
+// or of type Secret. The values are base64 encoded and the keys are like in
+// KubernetesConfigMap
+func KubernetesSecret(v interface{}, opts KubernetesOptions) ([]byte, error) {
+	_, secrets, err := kubernetesData(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	for k, value := range secrets {
+		secrets[k] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	var buf bytes.Buffer
+	writeKubernetesManifest(&buf, "Secret", opts)
+	buf.WriteString("type: Opaque\n")
+	writeKubernetesData(&buf, secrets)
+	return buf.Bytes(), nil
+}
+
+// kubernetesData returns the parameters of v that are not secret and the
+// secret ones, named as environment variables
+func kubernetesData(v interface{}, opts KubernetesOptions) (map[string]string, map[string]string, error) {
+	if len(opts.Name) == 0 {
+		return nil, nil, fmt.Errorf("the name of the manifest is required")
+	}
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("invalid value, it's not a struct, it's a %T", v)
+	}
+
+	tagNames := opts.TagNames
+	if len(tagNames) == 0 {
+		tagNames = []string{defaultTagName}
+	}
+	f := newFlattener(tagNames)
+	// The map keys can't be escaped in a data key, the ones that would not be
+	// read back are rejected
+	var mapKeyErr error
+	f.mapKey = func(key string) string {
+		if mapKeyErr == nil && (!kubernetesKey.MatchString(key) || strings.Contains(key, FieldSeparator)) {
+			mapKeyErr = fmt.Errorf("invalid map key %q, it can't be part of a Kubernetes data key", key)
+		}
+		return key
+	}
+	f.parseStruct(nil, val, false)
+	if mapKeyErr != nil {
+		return nil, nil, mapKeyErr
+	}
+
+	data := map[string]string{}
+	secrets := map[string]string{}
+	for k, value := range f.m {
+		key := opts.Prefix + strings.ToUpper(k)
+		if !kubernetesKey.MatchString(key) {
+			return nil, nil, fmt.Errorf("invalid key %q, it's not a valid Kubernetes data key", key)
+		}
+		if f.secrets[k] {
+			secrets[key] = value
+		} else {
+			data[key] = value
+		}
+	}
+
+	return data, secrets, nil
+}
+
+func writeKubernetesManifest(buf *bytes.Buffer, kind string, opts KubernetesOptions) {
+	fmt.Fprintf(buf, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, quote(opts.Name))
+	if len(opts.Namespace) != 0 {
+		fmt.Fprintf(buf, "  namespace: %s\n", quote(opts.Namespace))
+	}
+	if len(opts.Labels) != 0 {
+		buf.WriteString("  labels:\n")
+		for _, k := range sortedStringKeys(opts.Labels) {
+			fmt.Fprintf(buf, "    %s: %s\n", quote(k), quote(opts.Labels[k]))
+		}
+	}
+}
+
+func writeKubernetesData(buf *bytes.Buffer, data map[string]string) {
+	if len(data) == 0 {
+		buf.WriteString("data: {}\n")
+		return
+	}
+	buf.WriteString("data:\n")
+	for _, k := range sortedStringKeys(data) {
+		fmt.Fprintf(buf, "  %s: %s\n", k, quote(data[k]))
+	}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
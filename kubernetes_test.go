@@ -0,0 +1,126 @@
+package merger_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func ExampleKubernetesManifests() {
+	cfg := &ServiceConfig{
+		Port:  8080,
+		Hosts: []string{"localhost"},
+		Token: "s3cr3t",
+		Database: Database{
+			Host:        "db.local",
+			Credentials: Credentials{User: "admin", Password: "passw0rd"},
+		},
+	}
+
+	manifests, err := merger.KubernetesManifests(cfg, merger.KubernetesOptions{
+		Name:      "app",
+		Namespace: "prod",
+		Labels:    map[string]string{"app": "server"},
+		Prefix:    "APP_",
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(manifests))
+	// Output:
+	// apiVersion: v1
+	// kind: ConfigMap
+	// metadata:
+	//   name: "app"
+	//   namespace: "prod"
+	//   labels:
+	//     "app": "server"
+	// data:
+	//   APP_DATABASE__CREDENTIALS__USER: "admin"
+	//   APP_DATABASE__HOST: "db.local"
+	//   APP_HOSTS: "[localhost]"
+	//   APP_PORT: "8080"
+	// ---
+	// apiVersion: v1
+	// kind: Secret
+	// metadata:
+	//   name: "app"
+	//   namespace: "prod"
+	//   labels:
+	//     "app": "server"
+	// type: Opaque
+	// data:
+	//   APP_DATABASE__CREDENTIALS__PASSWORD: "cGFzc3cwcmQ="
+	//   APP_DATABASE__TOKENS: "W10="
+	//   APP_TOKEN: "czNjcjN0"
+}
+
+func TestKubernetesConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		opts    merger.KubernetesOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "simple",
+			v:    Simple{F1: 1, F2: "two"},
+			opts: merger.KubernetesOptions{Name: "simple"},
+			want: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"simple\"\ndata:\n  F1: \"1\"\n  F2: \"two\"\n",
+		},
+		{name: "missing name",
+			v:       Simple{},
+			wantErr: true,
+		},
+		{name: "invalid key",
+			v:       &Student{Grades: map[string]Grade{"math/1": {}}},
+			opts:    merger.KubernetesOptions{Name: "student"},
+			wantErr: true,
+		},
+		{name: "map key with spaces",
+			v:       &Student{Grades: map[string]Grade{"Computer Science": {}}},
+			opts:    merger.KubernetesOptions{Name: "student"},
+			wantErr: true,
+		},
+		{name: "map key with the separator",
+			v:       &Student{Grades: map[string]Grade{"a__b": {}}},
+			opts:    merger.KubernetesOptions{Name: "student"},
+			wantErr: true,
+		},
+		{name: "map key upper cased",
+			v:    &Student{Name: "Ann", Grades: map[string]Grade{"Math": {Number: 9}}},
+			opts: merger.KubernetesOptions{Name: "student"},
+			want: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"student\"\ndata:\n" +
+				"  ADDRESS__CITY: \"\"\n  ADDRESS__COUNTRY: \"\"\n  GPA: \"0\"\n" +
+				"  GRADES__MATH__NUMBER: \"9\"\n  GRADES__MATH__TEACHER: \"\"\n  NAME: \"Ann\"\n  TEXT_BOOKS: \"[]\"\n",
+		},
+		{name: "not a struct",
+			v:       "foo",
+			opts:    merger.KubernetesOptions{Name: "foo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.KubernetesConfigMap(tt.v, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KubernetesConfigMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("KubernetesConfigMap() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubernetesSecret(t *testing.T) {
+	got, err := merger.KubernetesSecret(Simple{F1: 1}, merger.KubernetesOptions{Name: "simple"})
+	if err != nil {
+		t.Fatalf("KubernetesSecret() error = %v", err)
+	}
+	want := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"simple\"\ntype: Opaque\ndata: {}\n"
+	if string(got) != want {
+		t.Errorf("KubernetesSecret() = \n%s\n, want \n%s", got, want)
+	}
+}
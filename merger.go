@@ -1,6 +1,8 @@
 package merger
 
 import (
+	"reflect"
+
 	"github.com/imdario/mergo"
 	"github.com/mitchellh/mapstructure"
 )
@@ -8,21 +10,109 @@ import (
 // FieldSeparator separates the fields of a struct when defining paramete names
 const FieldSeparator = "__"
 
+// Validator is implemented by the configurations that can check themselves.
+// The patches, the Store and the Watcher only accept valid configurations,
+// Merge and MergeMap don't call Validate
+type Validator interface {
+	Validate() error
+}
+
+// Merger merges maps and structs into a destination structure with custom
+// settings. The package functions Merge and MergeMap use a Merger with the
+// default settings
+type Merger struct {
+	unsetValue string
+	metadata   *Metadata
+	reveal     bool
+	schema     map[string]interface{}
+	schemaErr  error
+	keyCodec   KeyCodec
+	keyCase    KeyCase
+	strictKeys bool
+	types      *TypeRegistry
+}
+
+// Option modifies the settings of a Merger
+type Option func(*Merger)
+
+var defaultMerger = New()
+
+// New creates a Merger with the given options
+func New(opts ...Option) *Merger {
+	m := &Merger{
+		unsetValue: DefaultUnsetValue,
+		keyCodec:   DefaultKeyCodec,
+		keyCase:    LowerCase,
+		types:      DefaultTypeRegistry,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// WithUnsetValue sets the value that, in a source map, resets a field to its
+// zero value or removes a map entry. An empty value disables it, then only a
+// JSON `null` resets a field
+func WithUnsetValue(value string) Option {
+	return func(m *Merger) {
+		m.unsetValue = value
+	}
+}
+
+// WithMetadata sets the Metadata to fill with the parameters set and unset by
+// every merge
+func WithMetadata(md *Metadata) Option {
+	return func(m *Merger) {
+		m.metadata = md
+	}
+}
+
 // Merge merges the given map and optional structs into the dst structure
 func Merge(dst interface{}, srcMap map[string]string, srcs ...interface{}) error {
-	if err := mergeMap(dst, srcMap); err != nil {
+	return defaultMerger.Merge(dst, srcMap, srcs...)
+}
+
+// MergeMap merges the given maps into the dst structure. The first map has the
+// highest priority. Besides the usual values, the integers accept byte sizes
+// (10MiB, 1.5GB) and hexadecimal, octal and binary literals, the floats accept
+// percentages (75%) and the booleans accept yes, no, on and off
+func MergeMap(dst interface{}, srcMaps ...map[string]string) error {
+	return defaultMerger.MergeMap(dst, srcMaps...)
+}
+
+// Merge merges the given map and optional structs into the dst structure
+func (m *Merger) Merge(dst interface{}, srcMap map[string]string, srcs ...interface{}) error {
+	m.metadata.reset(m.keyCodec, reflect.TypeOf(dst))
+
+	if err := m.checkSources([]Source{{Values: srcMap}}); err != nil {
+		return err
+	}
+
+	if err := m.mergeMap(dst, srcMap); err != nil {
 		return err
 	}
 
 	return MergeStruct(dst, srcs...)
 }
 
-// MergeMap merges the given maps into the dst structure
-func MergeMap(dst interface{}, srcMaps ...map[string]string) error {
+// MergeMap merges the given maps into the dst structure. The first map has the
+// highest priority
+func (m *Merger) MergeMap(dst interface{}, srcMaps ...map[string]string) error {
+	m.metadata.reset(m.keyCodec, reflect.TypeOf(dst))
+
+	sources := make([]Source, 0, len(srcMaps))
+	for _, srcMap := range srcMaps {
+		sources = append(sources, Source{Values: srcMap})
+	}
+	if err := m.checkSources(sources); err != nil {
+		return err
+	}
+
 	for i := range srcMaps {
 		srcMap := srcMaps[len(srcMaps)-i-1]
 
-		if err := mergeMap(dst, srcMap); err != nil {
+		if err := m.mergeMap(dst, srcMap); err != nil {
 			return err
 		}
 	}
@@ -30,12 +120,68 @@ func MergeMap(dst interface{}, srcMaps ...map[string]string) error {
 	return nil
 }
 
-func mergeMap(dst interface{}, srcMap map[string]string) error {
-	m := TransformMap(srcMap)
+// checkSources checks the keys of the sources, with StrictKeys, and the result
+// of merging them with the JSON Schema, if the Merger has one
+func (m *Merger) checkSources(sources []Source) error {
+	if m.strictKeys {
+		srcMaps := make([]map[string]string, 0, len(sources))
+		for _, src := range sources {
+			srcMaps = append(srcMaps, src.Values)
+		}
+		if err := m.checkKeys(srcMaps); err != nil {
+			return err
+		}
+	}
+	if m.schema == nil && m.schemaErr == nil {
+		return nil
+	}
+	return m.checkSchema(m.effectiveMap(sources))
+}
+
+func (m *Merger) mergeMap(dst interface{}, srcMap map[string]string) error {
+	return m.mergeSource(dst, Source{Values: srcMap})
+}
+
+func (m *Merger) mergeSource(dst interface{}, src Source) error {
+	tm := m.transformSource(src)
+	unset := extractUnset(tm, m.unsetValue)
+	m.metadata.add(tm, unset, src.Name)
 
+	// The invalid values are reported before the keys are renamed to the
+	// field names
+	if err := decodeValues(reflect.TypeOf(dst), tm, nil, m.keyCodec, m.types); err != nil {
+		return redactError(err, secretValues(reflect.TypeOf(dst), tm, ""))
+	}
+
+	// The parameter names match the struct fields in any key case
+	tm = normalizeKeys(reflect.TypeOf(dst), tm, "")
+	if err := resolvePaths(reflect.TypeOf(dst), tm, src.dir(), nil, m.keyCodec); err != nil {
+		return err
+	}
+	if err := decode(dst, tm, "", m.types); err != nil {
+		return err
+	}
+
+	for _, path := range unset {
+		unsetPath(reflect.ValueOf(dst), path)
+	}
+
+	return nil
+}
+
+// decode decodes the nested map m into the dst structure, the fields are
+// identified by the given tag name or by the mapstructure tag if it's empty.
+// The strings are decoded to the types of the registry and, if they are
+// written for humans like 10MiB or 75%, to numbers and booleans
+func decode(dst interface{}, m map[string]interface{}, tagName string, types *TypeRegistry) error {
 	config := mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			types.decodeHook(),
+			mapstructure.DecodeHookFuncType(unitsDecodeHook),
+		),
 		WeaklyTypedInput: true,
 		Result:           &dst,
+		TagName:          tagName,
 	}
 
 	decoder, err := mapstructure.NewDecoder(&config)
@@ -44,7 +190,7 @@ func mergeMap(dst interface{}, srcMap map[string]string) error {
 	}
 
 	if err := decoder.Decode(m); err != nil {
-		return err
+		return redactError(err, secretValues(reflect.TypeOf(dst), m, tagName))
 	}
 
 	return nil
@@ -59,3 +205,10 @@ func MergeStruct(dst interface{}, srcs ...interface{}) error {
 	}
 	return nil
 }
+
+func validate(v interface{}) error {
+	if validator, ok := v.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
@@ -30,7 +30,7 @@ func Test_mergeMap(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := mergeMap(tt.args.dst, tt.args.srcMap)
+			err := New().mergeMap(tt.args.dst, tt.args.srcMap)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("mergeMap() error = %v, wantErr %v", err, tt.wantErr)
 			}
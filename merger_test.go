@@ -172,6 +172,17 @@ func TestMergeMap(t *testing.T) {
 			want:    &Simple{F1: 1, F2: "ten"},
 			wantErr: false,
 		},
+		{
+			name: "not validated",
+			args: args{
+				dst: &ServerConfig{},
+				srcMaps: []map[string]string{
+					map[string]string{"host": "localhost", "port": "-1"},
+				},
+			},
+			want:    &ServerConfig{Host: "localhost", Port: -1},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -210,6 +221,15 @@ func TestMergeStruct(t *testing.T) {
 			want:    &Simple{F1: 20, F2: "hola"},
 			wantErr: false,
 		},
+		{
+			name: "not validated",
+			args: args{
+				dst:  &ServerConfig{},
+				srcs: []interface{}{ServerConfig{Host: "localhost"}},
+			},
+			want:    &ServerConfig{Host: "localhost"},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
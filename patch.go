@@ -0,0 +1,521 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// patchOperation is an operation of a JSON Patch document (RFC 6902)
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from"`
+	Value interface{} `json:"value"`
+}
+
+// ApplyMergePatch applies the JSON Merge Patch document (RFC 7386) to the dst
+// structure. The fields are identified by their json tag, a `null` in the
+// patch removes the map entry or sets the field to its zero value. The result
+// is decoded like the maps merged with MergeMap and, if dst implements
+// Validator, it has to be valid. On error dst is not modified.
+func ApplyMergePatch(dst interface{}, patch []byte) error {
+	doc, err := toDocument(dst)
+	if err != nil {
+		return err
+	}
+
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return fmt.Errorf("invalid merge patch. %s", err)
+	}
+
+	return replaceWithDocument(dst, mergePatch(doc, p))
+}
+
+// ApplyJSONPatch applies the JSON Patch operations (RFC 6902) to the dst
+// structure. The fields are identified by their json tag. The result is
+// decoded like the maps merged with MergeMap and, if dst implements Validator,
+// it has to be valid. On error dst is not modified.
+func ApplyJSONPatch(dst interface{}, ops []byte) error {
+	doc, err := toDocument(dst)
+	if err != nil {
+		return err
+	}
+
+	var operations []patchOperation
+	if err := json.Unmarshal(ops, &operations); err != nil {
+		return fmt.Errorf("invalid JSON patch. %s", err)
+	}
+
+	for i, op := range operations {
+		if doc, err = applyOperation(doc, op); err != nil {
+			return fmt.Errorf("failed to apply the JSON patch operation #%d (%s %s). %s", i, op.Op, op.Path, err)
+		}
+	}
+
+	return replaceWithDocument(dst, doc)
+}
+
+// CreateMergePatch returns the JSON Merge Patch document (RFC 7386) to go from
+// the structure a to the structure b
+func CreateMergePatch(a, b interface{}) ([]byte, error) {
+	docA, err := toDocument(a)
+	if err != nil {
+		return nil, err
+	}
+	docB, err := toDocument(b)
+	if err != nil {
+		return nil, err
+	}
+
+	mA, okA := docA.(map[string]interface{})
+	mB, okB := docB.(map[string]interface{})
+	if !okA || !okB {
+		return json.Marshal(docB)
+	}
+
+	return json.Marshal(mergePatchDiff(mA, mB))
+}
+
+// toDocument returns the JSON representation of v as generic values
+func toDocument(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// replaceWithDocument decodes the document into a copy of dst where the
+// fields with a JSON representation have been set to the zero value, so the
+// fields removed from the document are reset. The copy replaces dst if it's
+// valid.
+func replaceWithDocument(dst interface{}, doc interface{}) error {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid patch result, it's not an object, it's a %T", doc)
+	}
+
+	ptrRef := reflect.ValueOf(dst)
+	if ptrRef.Kind() != reflect.Ptr || ptrRef.IsNil() {
+		return fmt.Errorf("invalid value, it's not a pointer, it's a %s. %v", ptrRef.Kind().String(), ptrRef)
+	}
+
+	result := deepCopy(ptrRef)
+	zeroDocumentFields(result.Elem())
+
+	m = nestEmbedded(ptrRef.Type(), m)
+	if err := decode(result.Interface(), m, defaultTagName, DefaultTypeRegistry); err != nil {
+		return err
+	}
+	if err := validate(result.Interface()); err != nil {
+		return err
+	}
+
+	ptrRef.Elem().Set(result.Elem())
+
+	return nil
+}
+
+// nestEmbedded returns the document with the fields of the embedded structs,
+// promoted to the parent object by encoding/json, moved to a nested object
+// named like the embedded struct, as mapstructure expects them. The fields of
+// the parent shadow the fields of the embedded structs, like in encoding/json
+func nestEmbedded(t reflect.Type, m map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		for k, v := range m {
+			if child, ok := v.(map[string]interface{}); ok {
+				m[k] = nestEmbedded(t.Elem(), child)
+			}
+		}
+		return m
+	case reflect.Struct:
+	default:
+		return m
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ignore := getName(field, []string{defaultTagName})
+		if ignore || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		ft, promoted := promotedStruct(field)
+		if !promoted {
+			if child, ok := result[name].(map[string]interface{}); ok {
+				result[name] = nestEmbedded(field.Type, child)
+			}
+			continue
+		}
+
+		// The promoted fields are the ones not defined by the parent
+		embedded := map[string]interface{}{}
+		for _, k := range jsonFieldNames(ft) {
+			v, ok := result[k]
+			if !ok || hasJSONField(t, k) {
+				continue
+			}
+			embedded[k] = v
+			delete(result, k)
+		}
+		if len(embedded) != 0 {
+			result[field.Name] = nestEmbedded(ft, embedded)
+		}
+	}
+
+	return result
+}
+
+// promotedStruct returns the struct type of the embedded field if its fields
+// are promoted to the parent by encoding/json, it's not named by the json tag
+func promotedStruct(field reflect.StructField) (reflect.Type, bool) {
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := strings.SplitN(field.Tag.Get(defaultTagName), ",", 2)[0]
+	return t, field.Anonymous && len(name) == 0 && t.Kind() == reflect.Struct
+}
+
+// jsonFieldNames returns the names, in JSON, of the fields of the struct type
+// t, including the fields promoted from its embedded structs
+func jsonFieldNames(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ignore := getName(field, []string{defaultTagName})
+		if ignore {
+			continue
+		}
+		if ft, promoted := promotedStruct(field); promoted {
+			names = append(names, jsonFieldNames(ft)...)
+			continue
+		}
+		if field.PkgPath == "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// hasJSONField returns true if the struct type t has a field, not promoted
+// from an embedded struct, with the given name in JSON
+func hasJSONField(t reflect.Type, name string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous || field.PkgPath != "" {
+			continue
+		}
+		if n, ignore := getName(field, []string{defaultTagName}); !ignore && n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// zeroDocumentFields sets to zero the exported fields of v that are not
+// ignored by the json tag, the fields of nested structs are zeroed one by one.
+// The parents are the structs embedding v, their fields shadow the fields of
+// v so these have no JSON representation and they are kept
+func zeroDocumentFields(v reflect.Value, parents ...reflect.Type) {
+	if v.Kind() != reflect.Struct {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, ignore := getName(field, []string{defaultTagName})
+		if field.PkgPath != "" || ignore || shadowed(parents, name) {
+			continue
+		}
+		if _, promoted := promotedStruct(field); promoted && field.Type.Kind() == reflect.Struct {
+			zeroDocumentFields(v.Field(i), append(parents, v.Type())...)
+			continue
+		}
+		zeroDocumentFields(v.Field(i))
+	}
+}
+
+// shadowed returns true if any of the structs has a field with the JSON name
+func shadowed(structs []reflect.Type, name string) bool {
+	for _, t := range structs {
+		if hasJSONField(t, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergePatch returns the result of applying the merge patch to the document
+// as defined in RFC 7386
+func mergePatch(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	d, ok := doc.(map[string]interface{})
+	if !ok {
+		d = map[string]interface{}{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = mergePatch(d[k], v)
+	}
+
+	return d
+}
+
+// mergePatchDiff returns the merge patch to go from the document a to b
+func mergePatchDiff(a, b map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+
+	for k, vB := range b {
+		vA, ok := a[k]
+		if !ok {
+			patch[k] = vB
+			continue
+		}
+
+		mA, okA := vA.(map[string]interface{})
+		mB, okB := vB.(map[string]interface{})
+		if okA && okB {
+			if p := mergePatchDiff(mA, mB); len(p) != 0 {
+				patch[k] = p
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(vA, vB) {
+			patch[k] = vB
+		}
+	}
+
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			patch[k] = nil
+		}
+	}
+
+	return patch
+}
+
+func applyOperation(doc interface{}, op patchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return pointerAdd(doc, path, op.Value)
+	case "remove":
+		doc, _, err = pointerRemove(doc, path)
+		return doc, err
+	case "replace":
+		if doc, _, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, op.Value)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			doc, value, err = pointerRemove(doc, from)
+		} else {
+			value, err = pointerGet(doc, from)
+			value = deepCopy(reflect.ValueOf(&value)).Elem().Interface()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	case "test":
+		value, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, fmt.Errorf("test failed, the value is %v", value)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePointer returns the reference tokens of a JSON Pointer (RFC 6901)
+func parsePointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.Replace(token, "~1", "/", -1)
+		tokens[i] = strings.Replace(token, "~0", "~", -1)
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex returns the index referenced by the token in an array of the
+// given length. To add an element the index can be the length of the array or
+// `-`, to reference the end of the array
+func arrayIndex(token string, length int, add bool) (int, error) {
+	max := length - 1
+	if add {
+		max = length
+		if token == "-" {
+			return length, nil
+		}
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return i, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%q not found", token)
+		}
+	}
+	return doc, nil
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("%q not found", token)
+		}
+		child, err := pointerAdd(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		if len(path) == 1 {
+			i, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		i, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := pointerAdd(node[i], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = child
+		return node, nil
+	default:
+		return nil, fmt.Errorf("%q not found", token)
+	}
+}
+
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	token := path[0]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%q not found", token)
+		}
+		if len(path) == 1 {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := pointerRemove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = child
+		return node, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(path) == 1 {
+			removed := node[i]
+			return append(node[:i], node[i+1:]...), removed, nil
+		}
+		child, removed, err := pointerRemove(node[i], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[i] = child
+		return node, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("%q not found", token)
+	}
+}
@@ -0,0 +1,271 @@
+package merger_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func newStudent() *Student {
+	return &Student{
+		Name:      "Pepe",
+		TextBooks: []string{"Book1", "Book2"},
+		Address:   Address{City: "San Diego", Country: "US"},
+		Grades: map[string]Grade{
+			"Science": Grade{Teacher: "Dr. Smith", Number: 89},
+			"Math":    Grade{Teacher: "Dr. Steve", Number: 99},
+		},
+		GPA: 94,
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     interface{}
+		patch   string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "empty",
+			dst:   newStudent(),
+			patch: `{}`,
+			want:  newStudent(),
+		},
+		{name: "replace and remove",
+			dst:   newStudent(),
+			patch: `{"name": "Juan", "address": {"city": null}, "grades": {"Math": null, "Science": {"number": 90}}, "gpa": null}`,
+			want: &Student{
+				Name:      "Juan",
+				TextBooks: []string{"Book1", "Book2"},
+				Address:   Address{Country: "US"},
+				Grades: map[string]Grade{
+					"Science": Grade{Teacher: "Dr. Smith", Number: 90},
+				},
+			},
+		},
+		{name: "replace list",
+			dst:   newStudent(),
+			patch: `{"text_books": ["Book3"]}`,
+			want: func() *Student {
+				s := newStudent()
+				s.TextBooks = []string{"Book3"}
+				return s
+			}(),
+		},
+		{name: "invalid patch",
+			dst:     newStudent(),
+			patch:   `{"name": `,
+			want:    newStudent(),
+			wantErr: true,
+		},
+		{name: "not an object",
+			dst:     newStudent(),
+			patch:   `"Juan"`,
+			want:    newStudent(),
+			wantErr: true,
+		},
+		{name: "invalid result is not applied",
+			dst:     &ServerConfig{Host: "localhost", Port: 8080},
+			patch:   `{"host": "example.com", "port": null}`,
+			want:    &ServerConfig{Host: "localhost", Port: 8080},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := merger.ApplyMergePatch(tt.dst, []byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyMergePatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("ApplyMergePatch() = %+v, want %+v", tt.dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     string
+		want    *Student
+		wantErr bool
+	}{
+		{name: "add, replace and remove",
+			ops: `[
+				{"op": "add", "path": "/text_books/-", "value": "Book3"},
+				{"op": "add", "path": "/text_books/0", "value": "Book0"},
+				{"op": "replace", "path": "/address/city", "value": "LA"},
+				{"op": "remove", "path": "/grades/Math"},
+				{"op": "add", "path": "/grades/Computer Science", "value": {"teacher": "Dr. Who", "number": 100}}
+			]`,
+			want: &Student{
+				Name:      "Pepe",
+				TextBooks: []string{"Book0", "Book1", "Book2", "Book3"},
+				Address:   Address{City: "LA", Country: "US"},
+				Grades: map[string]Grade{
+					"Science":          Grade{Teacher: "Dr. Smith", Number: 89},
+					"Computer Science": Grade{Teacher: "Dr. Who", Number: 100},
+				},
+				GPA: 94,
+			},
+		},
+		{name: "move, copy and test",
+			ops: `[
+				{"op": "test", "path": "/name", "value": "Pepe"},
+				{"op": "move", "from": "/grades/Math", "path": "/grades/Algebra"},
+				{"op": "copy", "from": "/address/city", "path": "/name"},
+				{"op": "remove", "path": "/text_books/1"}
+			]`,
+			want: &Student{
+				Name:      "San Diego",
+				TextBooks: []string{"Book1"},
+				Address:   Address{City: "San Diego", Country: "US"},
+				Grades: map[string]Grade{
+					"Science": Grade{Teacher: "Dr. Smith", Number: 89},
+					"Algebra": Grade{Teacher: "Dr. Steve", Number: 99},
+				},
+				GPA: 94,
+			},
+		},
+		{name: "failed test",
+			ops: `[
+				{"op": "replace", "path": "/name", "value": "Juan"},
+				{"op": "test", "path": "/gpa", "value": 100}
+			]`,
+			want:    newStudent(),
+			wantErr: true,
+		},
+		{name: "path not found",
+			ops:     `[{"op": "remove", "path": "/grades/History"}]`,
+			want:    newStudent(),
+			wantErr: true,
+		},
+		{name: "invalid index",
+			ops:     `[{"op": "add", "path": "/text_books/5", "value": "Book5"}]`,
+			want:    newStudent(),
+			wantErr: true,
+		},
+		{name: "unknown operation",
+			ops:     `[{"op": "delete", "path": "/name"}]`,
+			want:    newStudent(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newStudent()
+			err := merger.ApplyJSONPatch(dst, []byte(tt.ops))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyJSONPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("ApplyJSONPatch() = %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	a := newStudent()
+	b := newStudent()
+	b.Name = "Juan"
+	b.Address.City = "LA"
+	delete(b.Grades, "Math")
+	b.TextBooks = nil
+
+	patch, err := merger.CreateMergePatch(a, b)
+	if err != nil {
+		t.Fatalf("CreateMergePatch() error = %v", err)
+	}
+
+	var got, want interface{}
+	json.Unmarshal(patch, &got)
+	json.Unmarshal([]byte(`{"name": "Juan", "address": {"city": "LA"}, "grades": {"Math": null}, "text_books": null}`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateMergePatch() = %s", patch)
+	}
+
+	if err := merger.ApplyMergePatch(a, patch); err != nil {
+		t.Fatalf("ApplyMergePatch() error = %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("ApplyMergePatch(CreateMergePatch()) = %+v, want %+v", a, b)
+	}
+}
+
+// PatchBase and PatchTLS are exported, mapstructure can't set the unexported
+// embedded structs
+type PatchBase struct {
+	Host string `json:"host"`
+	Name string `json:"name"`
+}
+
+type patchEmbedded struct {
+	PatchBase
+	*PatchTLS
+	Port int    `json:"port"`
+	Name string `json:"name"`
+}
+
+type PatchTLS struct {
+	CA string `json:"ca"`
+}
+
+func TestApplyPatchEmbedded(t *testing.T) {
+	newEmbedded := func() *patchEmbedded {
+		return &patchEmbedded{
+			PatchBase: PatchBase{Host: "h", Name: "base"},
+			PatchTLS:  &PatchTLS{CA: "ca.pem"},
+			Port:      1,
+			Name:      "outer",
+		}
+	}
+
+	tests := []struct {
+		name  string
+		apply func(dst interface{}) error
+		want  *patchEmbedded
+	}{
+		{name: "merge patch",
+			apply: func(dst interface{}) error {
+				return merger.ApplyMergePatch(dst, []byte(`{"port": 2}`))
+			},
+			want: &patchEmbedded{
+				PatchBase: PatchBase{Host: "h", Name: "base"},
+				PatchTLS:  &PatchTLS{CA: "ca.pem"},
+				Port:      2,
+				Name:      "outer",
+			},
+		},
+		{name: "json patch",
+			apply: func(dst interface{}) error {
+				return merger.ApplyJSONPatch(dst, []byte(`[
+					{"op": "replace", "path": "/host", "value": "example.com"},
+					{"op": "replace", "path": "/ca", "value": "other.pem"},
+					{"op": "replace", "path": "/name", "value": "renamed"}
+				]`))
+			},
+			want: &patchEmbedded{
+				PatchBase: PatchBase{Host: "example.com", Name: "base"},
+				PatchTLS:  &PatchTLS{CA: "other.pem"},
+				Port:      1,
+				Name:      "renamed",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newEmbedded()
+			if err := tt.apply(dst); err != nil {
+				t.Fatalf("apply error = %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("apply = %+v %+v, want %+v %+v", dst, dst.PatchTLS, tt.want, tt.want.PatchTLS)
+			}
+		})
+	}
+}
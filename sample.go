@@ -0,0 +1,138 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SampleMapKey is the key used in the sample configuration for the empty maps
+const SampleMapKey = "key"
+
+// SecretPlaceholder replaces the secret values in the sample configuration
+const SecretPlaceholder = "<secret>"
+
+// SampleOptions are the options to generate a sample configuration file
+type SampleOptions struct {
+	// Format of the sample configuration, YAML by default
+	Format Format
+	// Prefix is added to the name of every environment variable, only for the
+	// dotenv format
+	Prefix string
+	// TagNames are the tags used to name the parameters, in order of importance,
+	// like in TransformToMap. By default it's the json tag
+	TagNames []string
+}
+
+// Sample returns an example configuration file for the configuration v, a
+// struct or a pointer to a struct. The parameters are named like in
+// TransformToMap, keeping the case, and nested by struct and map key except in
+// the dotenv format, where every parameter is an environment variable in upper
+// case with the given prefix. The value is taken from the `default` tag or, if
+// there is no tag, from v. The `description` tag of the fields is added as a
+// comment, except in JSON, and the secret values are replaced by
+// SecretPlaceholder. The empty maps have one entry with the key SampleMapKey
+func Sample(v interface{}, opts SampleOptions) ([]byte, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid value, it's not a struct, it's a %T", v)
+	}
+
+	if len(opts.Format) == 0 {
+		opts.Format = YAML
+	}
+
+	w := &fieldWalker{tagNames: opts.TagNames, mapKey: SampleMapKey}
+	root := newObjectNode("")
+	// The flat names of the parameters by path, the map keys keep their case
+	// even in the dotenv format
+	names := map[string]string{}
+	for _, field := range w.walkFields(val.Type(), val) {
+		root.set(field.Path, sampleValue(field), sampleComment(field))
+		if opts.Format == Dotenv {
+			names[pathKey(field.Path)] = opts.Prefix + field.flatName(strings.ToUpper)
+		} else {
+			names[pathKey(field.Path)] = field.flatName(PreserveCase.apply)
+		}
+	}
+
+	flatName := func(path []string) string {
+		return names[pathKey(path)]
+	}
+
+	var buf bytes.Buffer
+	if err := root.write(&buf, opts.Format, flatName); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pathKey returns a map key for the path
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func sampleComment(field fieldInfo) string {
+	if !field.Required {
+		return field.Description
+	}
+	if len(field.Description) == 0 {
+		return "Required"
+	}
+	return field.Description + " (required)"
+}
+
+// sampleValue returns the value of the parameter with its type, so it's
+// written like a number, boolean, list or string
+func sampleValue(field fieldInfo) interface{} {
+	switch {
+	case field.Secret && (field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array):
+		return []interface{}{SecretPlaceholder}
+	case field.Secret:
+		return SecretPlaceholder
+	case field.HasDefault:
+		return defaultValue(field.Type, field.Default)
+	case field.Value.IsValid():
+		return typedValue(field.Value)
+	default:
+		return typedValue(reflect.Zero(field.Type))
+	}
+}
+
+func typedValue(v reflect.Value) interface{} {
+	if DefaultTypeRegistry.isValueType(v.Type()) {
+		return valueString(v)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32:
+		// Keep the float32 precision, i.e. 0.1 and not 0.10000000149011612
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(v.Float(), 'g', -1, 32), 64)
+		return f
+	case reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return valueString(v)
+		}
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, typedValue(v.Index(i)))
+		}
+		return items
+	default:
+		return valueString(v)
+	}
+}
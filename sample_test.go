@@ -0,0 +1,163 @@
+package merger_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func ExampleSample() {
+	cfg := &ServiceConfig{
+		Hosts: []string{"localhost"},
+		Token: "s3cr3t",
+	}
+
+	sample, err := merger.Sample(cfg, merger.SampleOptions{Format: merger.YAML})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(sample))
+	// Output:
+	// # Port to listen
+	// port: 8080
+	// # Allowed hosts
+	// hosts:
+	//   - "localhost"
+	// # API token (required)
+	// token: "<secret>"
+	// database:
+	//   host: ""
+	//   credentials:
+	//     user: ""
+	//     password: "<secret>"
+	//   tokens:
+	//     - "<secret>"
+	// grades:
+	//   key:
+	//     teacher: ""
+	//     number: 0.0
+}
+
+func TestSample(t *testing.T) {
+	cfg := &ServiceConfig{
+		Hosts:  []string{"a", "b c"},
+		Token:  "s3cr3t",
+		Grades: map[string]Grade{"math 1": {Teacher: "Bob", Number: 9.1}},
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		opts    merger.SampleOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "toml",
+			v:    cfg,
+			opts: merger.SampleOptions{Format: merger.TOML},
+			want: `# Port to listen
+port = 8080
+# Allowed hosts
+hosts = ["a", "b c"]
+# API token (required)
+token = "<secret>"
+
+[database]
+host = ""
+tokens = ["<secret>"]
+
+[database.credentials]
+user = ""
+password = "<secret>"
+
+[grades."math 1"]
+teacher = "Bob"
+number = 9.1
+`,
+		},
+		{name: "dotenv",
+			v:    cfg,
+			opts: merger.SampleOptions{Format: merger.Dotenv, Prefix: "APP_"},
+			want: `# Port to listen
+APP_PORT=8080
+# Allowed hosts
+APP_HOSTS="[a, b c]"
+# API token (required)
+APP_TOKEN="<secret>"
+APP_DATABASE__HOST=
+APP_DATABASE__CREDENTIALS__USER=
+APP_DATABASE__CREDENTIALS__PASSWORD="<secret>"
+APP_DATABASE__TOKENS="[<secret>]"
+APP_GRADES__math%201__TEACHER=Bob
+APP_GRADES__math%201__NUMBER=9.1
+`,
+		},
+		{name: "properties",
+			v: &ServiceConfig{
+				Token:  "s3cr3t",
+				Grades: map[string]Grade{"Math 1": {Teacher: "Bob"}},
+			},
+			opts: merger.SampleOptions{Format: merger.Properties},
+			want: `# Port to listen
+port=8080
+# Allowed hosts
+hosts=[]
+# API token (required)
+token=<secret>
+database__host=
+database__credentials__user=
+database__credentials__password=<secret>
+database__tokens=[<secret>]
+grades__Math%201__teacher=Bob
+grades__Math%201__number=0
+`,
+		},
+		{name: "json",
+			v:    Simple{F1: 1, F2: "x"},
+			opts: merger.SampleOptions{Format: merger.JSON},
+			want: `{
+  "F1": 1,
+  "F2": "x"
+}
+`,
+		},
+		{name: "unknown format",
+			v:       Simple{},
+			opts:    merger.SampleOptions{Format: "xml"},
+			wantErr: true,
+		},
+		{name: "not a struct",
+			v:       "foo",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := merger.Sample(tt.v, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Sample() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Sample() = \n%s\n, want \n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleJSONDecodes(t *testing.T) {
+	sample, err := merger.Sample(&ServiceConfig{Hosts: []string{"localhost"}}, merger.SampleOptions{Format: merger.JSON})
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+
+	var got ServiceConfig
+	if err := json.Unmarshal(sample, &got); err != nil {
+		t.Fatalf("the JSON sample is invalid. %s\n%s", err, sample)
+	}
+	if got.Port != 8080 || len(got.Hosts) != 1 || got.Token != merger.SecretPlaceholder {
+		t.Errorf("decoded sample = %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// SchemaDraft is the JSON Schema draft of the schemas generated by Schema
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Tags with the documentation of a field
+const (
+	defaultValueTagName = "default"
+	descriptionTagName  = "description"
+)
+
+// Schema returns the JSON Schema of the given struct, or pointer to struct.
+// The properties are named like TransformToMap does, using the given tags in
+// order of importance (json by default), but keeping the case. The `default`
+// and `description` tags of the fields go to the schema and the fields tagged
+// with `merger:"required"` are required
+func Schema(v interface{}, tagNames ...string) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid value, it's not a struct, it's a %T", v)
+	}
+
+	if len(tagNames) == 0 {
+		tagNames = []string{defaultTagName}
+	}
+
+	s := typeSchema(t, tagNames, map[reflect.Type]bool{})
+	s["$schema"] = SchemaDraft
+	s["title"] = t.Name()
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func typeSchema(t reflect.Type, tagNames []string, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == secretType {
+		return map[string]interface{}{"type": "string", "writeOnly": true}
+	}
+	// The registered types and the TextMarshalers are parameters written as
+	// strings, i.e. durations or IP addresses
+	if DefaultTypeRegistry.isValueType(t) {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), tagNames, visiting),
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), tagNames, visiting),
+		}
+	case reflect.Struct:
+		return structSchema(t, tagNames, visiting)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type, tagNames []string, visiting map[reflect.Type]bool) map[string]interface{} {
+	s := map[string]interface{}{"type": "object"}
+
+	// A recursive type is described only the first time
+	if visiting[t] {
+		return s
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, ignore := getName(field, tagNames)
+		if ignore {
+			continue
+		}
+
+		p := typeSchema(field.Type, tagNames, visiting)
+		if description, ok := field.Tag.Lookup(descriptionTagName); ok {
+			p["description"] = description
+		}
+		if value, ok := field.Tag.Lookup(defaultValueTagName); ok {
+			p["default"] = defaultValue(field.Type, value)
+		}
+		if isSecret(field) {
+			p["writeOnly"] = true
+		}
+		if hasOption(field, "required") {
+			required = append(required, name)
+		}
+
+		properties[name] = p
+	}
+
+	s["properties"] = properties
+	if len(required) != 0 {
+		s["required"] = required
+	}
+
+	return s
+}
+
+// defaultValue returns the value of the default tag with the type of the
+// field, the slices are defined like in the maps merged with MergeMap
+func defaultValue(t reflect.Type, value string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if DefaultTypeRegistry.isValueType(t) {
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return i
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u, err := strconv.ParseUint(value, 0, 64); err == nil {
+			return u
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		items := []interface{}{}
+		for _, item := range transformToSlice(value) {
+			items = append(items, defaultValue(t.Elem(), item))
+		}
+		return items
+	case reflect.Map, reflect.Struct:
+		if isJSONStruct(value) {
+			return transformJSONToStruct(value)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,122 @@
+package merger_test
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johandry/merger"
+)
+
+type AppConfig struct {
+	Name     string            `json:"name" merger:"required" description:"Name of the application"`
+	Port     uint16            `json:"port,omitempty" default:"8080"`
+	Debug    bool              `json:"debug" default:"false"`
+	Ratio    float64           `json:"ratio" default:"0.5"`
+	Hosts    []string          `json:"hosts" default:"a, b"`
+	Password merger.Secret     `json:"password"`
+	Labels   map[string]string `json:"labels"`
+	DB       *Database         `json:"db" merger:"required"`
+	Ignored  string            `json:"-"`
+	Parent   *AppConfig        `json:"parent"`
+	internal int
+}
+
+func TestSchema(t *testing.T) {
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "AppConfig",
+		"type": "object",
+		"required": ["name", "db"],
+		"properties": {
+			"name": {"type": "string", "description": "Name of the application"},
+			"port": {"type": "integer", "minimum": 0, "default": 8080},
+			"debug": {"type": "boolean", "default": false},
+			"ratio": {"type": "number", "default": 0.5},
+			"hosts": {"type": "array", "items": {"type": "string"}, "default": ["a", "b"]},
+			"password": {"type": "string", "writeOnly": true},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"db": {
+				"type": "object",
+				"properties": {
+					"host": {"type": "string"},
+					"credentials": {
+						"type": "object",
+						"properties": {
+							"user": {"type": "string"},
+							"password": {"type": "string", "writeOnly": true}
+						}
+					},
+					"tokens": {"type": "array", "items": {"type": "string"}, "writeOnly": true}
+				}
+			},
+			"parent": {"type": "object"}
+		}
+	}`
+
+	got, err := merger.Schema(&AppConfig{})
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("Schema() returned an invalid JSON. %s", err)
+	}
+	json.Unmarshal([]byte(want), &wantValue)
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("Schema() = %s", got)
+	}
+}
+
+type ServerTimes struct {
+	Timeout time.Duration  `json:"timeout" default:"1m30s"`
+	Started time.Time      `json:"started"`
+	Addr    net.IP         `json:"addr"`
+	Idle    *time.Duration `json:"idle"`
+}
+
+func TestSchema_RegisteredTypes(t *testing.T) {
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "ServerTimes",
+		"type": "object",
+		"properties": {
+			"timeout": {"type": "string", "default": "1m30s"},
+			"started": {"type": "string"},
+			"addr": {"type": "string"},
+			"idle": {"type": "string"}
+		}
+	}`
+
+	got, err := merger.Schema(&ServerTimes{})
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("Schema() returned an invalid JSON. %s", err)
+	}
+	json.Unmarshal([]byte(want), &wantValue)
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("Schema() = %s", got)
+	}
+
+	// The schema accepts its own default values
+	m := merger.New(merger.WithSchema(got))
+	err = m.MergeMap(&ServerTimes{}, map[string]string{"timeout": "1m30s", "started": "2024-01-02T03:04:05Z", "addr": "10.0.0.1"})
+	if err != nil {
+		t.Errorf("MergeMap() error = %v", err)
+	}
+}
+
+func TestSchema_Errors(t *testing.T) {
+	for _, v := range []interface{}{nil, 10, map[string]string{}} {
+		if _, err := merger.Schema(v); err == nil {
+			t.Errorf("Schema(%v) error = nil, want an error", v)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaViolation is a value of the source maps that does not comply with the
+// JSON Schema. The path is the parameter name encoded with the KeyCodec of the
+// Merger
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+// SchemaError is the error returned when the source maps do not comply with
+// the JSON Schema set with WithSchema
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, fmt.Sprintf("* %s: %s", v.Path, v.Message))
+	}
+	return fmt.Sprintf("%d schema violation(s):\n\n%s", len(e.Violations), strings.Join(msgs, "\n"))
+}
+
+// WithSchema sets the JSON Schema that the source maps have to comply with
+// before they are decoded. It supports a subset of JSON Schema: type,
+// properties, required, additionalProperties, items, enum, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength, minItems,
+// maxItems and pattern. The values of the maps are weakly typed, like when
+// they are decoded, so the strings "10" and "10MiB" are valid integers, "75%"
+// is a valid number and "yes" is a valid boolean. The property names are not
+// case sensitive
+func WithSchema(schema []byte) Option {
+	return func(m *Merger) {
+		m.schema = nil
+		m.schemaErr = nil
+
+		var s map[string]interface{}
+		if err := json.Unmarshal(schema, &s); err != nil {
+			m.schemaErr = fmt.Errorf("invalid JSON schema. %s", err)
+			return
+		}
+		m.schema = s
+	}
+}
+
+// checkSchema checks the nested map with the schema of the Merger, if any
+func (m *Merger) checkSchema(nm map[string]interface{}) error {
+	if m.schemaErr != nil {
+		return m.schemaErr
+	}
+	if m.schema == nil {
+		return nil
+	}
+
+	violations := checkValue(m.schema, nm, nil, m.keyCodec)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+
+	return &SchemaError{Violations: violations}
+}
+
+// effectiveMap returns the nested map resulting of merging the given sources,
+// the first source has the highest priority
+func (m *Merger) effectiveMap(sources []Source) map[string]interface{} {
+	result := map[string]interface{}{}
+	for i := range sources {
+		tm := m.transformSource(sources[len(sources)-i-1])
+		for _, path := range extractUnset(tm, m.unsetValue) {
+			deletePath(result, path)
+		}
+		result = mergeTwoMaps(result, tm, true)
+	}
+	return result
+}
+
+func deletePath(m map[string]interface{}, path []string) {
+	for len(path) > 1 {
+		child, ok := m[path[0]].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m, path = child, path[1:]
+	}
+	delete(m, path[0])
+}
+
+func checkValue(schema map[string]interface{}, v interface{}, path []string, codec KeyCodec) []SchemaViolation {
+	violation := func(format string, a ...interface{}) []SchemaViolation {
+		return []SchemaViolation{{
+			Path:    codec.join(path),
+			Message: fmt.Sprintf(format, a...),
+		}}
+	}
+
+	if t, ok := schema["type"]; ok {
+		types := schemaTypes(t)
+		matched := false
+		for _, typ := range types {
+			if matchesType(v, typ) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return violation("expected type %s", strings.Join(types, " or "))
+		}
+	}
+
+	violations := []SchemaViolation{}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, violation("the value is not one of the allowed values")...)
+		}
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		violations = append(violations, checkObject(schema, value, path, codec)...)
+	case []interface{}:
+		violations = append(violations, checkArray(schema, value, path, codec)...)
+	case []string:
+		items := make([]interface{}, len(value))
+		for i := range value {
+			items[i] = value[i]
+		}
+		violations = append(violations, checkArray(schema, items, path, codec)...)
+	case nil:
+	default:
+		violations = append(violations, checkScalar(schema, value, path, codec)...)
+	}
+
+	return violations
+}
+
+func checkObject(schema map[string]interface{}, m map[string]interface{}, path []string, codec KeyCodec) []SchemaViolation {
+	violations := []SchemaViolation{}
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			name := fmt.Sprint(r)
+			if _, ok := lookupKey(m, name); !ok {
+				violations = append(violations, SchemaViolation{
+					Path:    codec.join(append(append([]string{}, path...), name)),
+					Message: "required parameter is missing",
+				})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// The keys matching the same property, not case sensitive, are decoded to
+	// the same field so they are checked together
+	matched := map[string]interface{}{}
+	for _, k := range keys {
+		if name, ok := lookupKey(properties, k); ok {
+			if prev, ok := matched[name].(map[string]interface{}); ok && isMap(m[k]) {
+				matched[name] = mergeTwoMaps(prev, m[k].(map[string]interface{}), false)
+			} else {
+				matched[name] = m[k]
+			}
+			continue
+		}
+
+		childPath := append(append([]string{}, path...), k)
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, SchemaViolation{
+					Path:    codec.join(childPath),
+					Message: "unknown parameter",
+				})
+			}
+		case map[string]interface{}:
+			violations = append(violations, checkValue(additional, m[k], childPath, codec)...)
+		}
+	}
+
+	for name, v := range matched {
+		if p, ok := properties[name].(map[string]interface{}); ok {
+			violations = append(violations, checkValue(p, v, append(append([]string{}, path...), name), codec)...)
+		}
+	}
+
+	return violations
+}
+
+func checkArray(schema map[string]interface{}, items []interface{}, path []string, codec KeyCodec) []SchemaViolation {
+	violations := []SchemaViolation{}
+	p := codec.join(path)
+
+	if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(items)) < min {
+		violations = append(violations, SchemaViolation{Path: p, Message: fmt.Sprintf("expected at least %v items", min)})
+	}
+	if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(items)) > max {
+		violations = append(violations, SchemaViolation{Path: p, Message: fmt.Sprintf("expected at most %v items", max)})
+	}
+
+	if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range items {
+			itemPath := append(append([]string{}, path...), strconv.Itoa(i))
+			violations = append(violations, checkValue(itemSchema, item, itemPath, codec)...)
+		}
+	}
+
+	return violations
+}
+
+func checkScalar(schema map[string]interface{}, v interface{}, path []string, codec KeyCodec) []SchemaViolation {
+	violations := []SchemaViolation{}
+	p := codec.join(path)
+	add := func(format string, a ...interface{}) {
+		violations = append(violations, SchemaViolation{Path: p, Message: fmt.Sprintf(format, a...)})
+	}
+
+	// A single value is weakly typed to an array of one item
+	if _, ok := schema["items"]; ok {
+		return checkArray(schema, []interface{}{v}, path, codec)
+	}
+
+	if n, ok := toNumber(v); ok {
+		if min, ok := schemaNumber(schema, "minimum"); ok && n < min {
+			add("expected a value greater than or equal to %v", min)
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && n > max {
+			add("expected a value less than or equal to %v", max)
+		}
+		if min, ok := schemaNumber(schema, "exclusiveMinimum"); ok && n <= min {
+			add("expected a value greater than %v", min)
+		}
+		if max, ok := schemaNumber(schema, "exclusiveMaximum"); ok && n >= max {
+			add("expected a value less than %v", max)
+		}
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return violations
+	}
+
+	length := float64(utf8.RuneCountInString(s))
+	if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+		add("expected at least %v characters", min)
+	}
+	if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+		add("expected at most %v characters", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			add("invalid pattern %q in the schema", pattern)
+		} else if !re.MatchString(s) {
+			add("the value does not match the pattern %q", pattern)
+		}
+	}
+
+	return violations
+}
+
+// lookupKey returns the key of the map equal, not case sensitive, to the name
+func lookupKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func schemaTypes(t interface{}) []string {
+	switch typ := t.(type) {
+	case string:
+		return []string{typ}
+	case []interface{}:
+		types := make([]string, 0, len(typ))
+		for _, t := range typ {
+			types = append(types, fmt.Sprint(t))
+		}
+		return types
+	}
+	return nil
+}
+
+func schemaNumber(schema map[string]interface{}, keyword string) (float64, bool) {
+	n, ok := schema[keyword].(float64)
+	return n, ok
+}
+
+// matchesType returns true if the value can be decoded to the JSON type
+func matchesType(v interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		switch v.(type) {
+		case []interface{}, []string, string, float64, bool:
+			return true
+		}
+		return false
+	case "string":
+		switch v.(type) {
+		case string, float64, bool:
+			return true
+		}
+		return false
+	case "integer":
+		n, ok := toNumber(v)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := toNumber(v)
+		return ok
+	case "boolean":
+		switch value := v.(type) {
+		case bool:
+			return true
+		case string:
+			_, err := strconv.ParseBool(value)
+			_, word := boolWords[strings.ToLower(strings.TrimSpace(value))]
+			return err == nil || word || len(value) == 0
+		}
+		return false
+	case "null":
+		return v == nil
+	}
+	return true
+}
+
+// toNumber returns the number of the value. Like when it's decoded, a string
+// can be an integer literal in any base, a byte size or a percentage
+func toNumber(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case string:
+		value = strings.TrimSpace(value)
+		if i, ok, err := parseInt(value); ok {
+			return float64(i), err == nil
+		}
+		if strings.HasSuffix(value, "%") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+			return f / 100, err == nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,203 @@
+package merger_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+const studentSchema = `{
+	"type": "object",
+	"required": ["name", "address"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "pattern": "^[A-Z]"},
+		"text_books": {"type": "array", "maxItems": 2, "items": {"type": "string", "enum": ["Book1", "Book2", "Book3"]}},
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {
+				"city": {"type": "string"},
+				"country": {"type": "string", "enum": ["US", "MX"]}
+			}
+		},
+		"grades": {
+			"type": "object",
+			"additionalProperties": {
+				"type": "object",
+				"properties": {
+					"number": {"type": "number", "minimum": 0, "maximum": 100}
+				}
+			}
+		},
+		"gpa": {"type": "number"}
+	}
+}`
+
+func TestWithSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		srcMaps []map[string]string
+		want    []merger.SchemaViolation
+	}{
+		{name: "valid",
+			schema: studentSchema,
+			srcMaps: []map[string]string{
+				map[string]string{"Name": "John", "Address__City": "LA"},
+				map[string]string{"text_books": "Book1, Book3", "grades__Science__number": "99.5", "address__country": "US"},
+			},
+		},
+		{name: "valid JSON value",
+			schema: studentSchema,
+			srcMaps: []map[string]string{
+				map[string]string{"name": "John", "address": `{"city": "LA", "country": "MX"}`, "gpa": "3.5"},
+			},
+		},
+		{name: "violations",
+			schema: studentSchema,
+			srcMaps: []map[string]string{
+				map[string]string{
+					"name":                    "j",
+					"text_books":              "Book1, Book2, Book4",
+					"address__country":        "CA",
+					"grades__Science__number": "101",
+					"grades__Math__number":    "A+",
+					"age":                     "20",
+				},
+			},
+			want: []merger.SchemaViolation{
+				{Path: "address__city", Message: "required parameter is missing"},
+				{Path: "address__country", Message: "the value is not one of the allowed values"},
+				{Path: "age", Message: "unknown parameter"},
+				{Path: "grades__Math__number", Message: "expected type number"},
+				{Path: "grades__Science__number", Message: "expected a value less than or equal to 100"},
+				{Path: "name", Message: "expected at least 2 characters"},
+				{Path: "name", Message: `the value does not match the pattern "^[A-Z]"`},
+				{Path: "text_books", Message: "expected at most 2 items"},
+				{Path: "text_books__2", Message: "the value is not one of the allowed values"},
+			},
+		},
+		{name: "missing in every layer",
+			schema: studentSchema,
+			srcMaps: []map[string]string{
+				map[string]string{"Name": "John"},
+				map[string]string{"Address__Country": "US"},
+			},
+			want: []merger.SchemaViolation{
+				{Path: "address__city", Message: "required parameter is missing"},
+			},
+		},
+		{name: "unset required",
+			schema: studentSchema,
+			srcMaps: []map[string]string{
+				map[string]string{"Name": "!unset"},
+				map[string]string{"Name": "John", "Address__City": "LA"},
+			},
+			want: []merger.SchemaViolation{
+				{Path: "name", Message: "required parameter is missing"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := &Student{}
+			err := merger.New(merger.WithSchema([]byte(tt.schema))).MergeMap(dst, tt.srcMaps...)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("MergeMap() error = %v", err)
+				}
+				return
+			}
+
+			schemaErr, ok := err.(*merger.SchemaError)
+			if !ok {
+				t.Fatalf("MergeMap() error = %v, want a SchemaError", err)
+			}
+			if !reflect.DeepEqual(schemaErr.Violations, tt.want) {
+				t.Errorf("MergeMap() violations = %+v, want %+v", schemaErr.Violations, tt.want)
+			}
+			if !reflect.DeepEqual(dst, &Student{}) {
+				t.Errorf("MergeMap() modified the destination = %+v", dst)
+			}
+		})
+	}
+}
+
+func TestWithSchema_Generated(t *testing.T) {
+	schema, err := merger.Schema(&AppConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := merger.New(merger.WithSchema(schema))
+
+	err = m.Merge(&AppConfig{}, map[string]string{"name": "app", "port": "8080", "db__host": "localhost"})
+	if err != nil {
+		t.Errorf("Merge() error = %v", err)
+	}
+
+	err = m.Merge(&AppConfig{}, map[string]string{"name": "app", "port": "-1"})
+	if _, ok := err.(*merger.SchemaError); !ok {
+		t.Errorf("Merge() error = %v, want a SchemaError", err)
+	}
+}
+
+func TestWithSchema_HumanValues(t *testing.T) {
+	schema, err := merger.Schema(&unitsConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := merger.New(merger.WithSchema(schema))
+
+	err = m.MergeMap(&unitsConfig{}, map[string]string{
+		"max_body": "10MiB",
+		"buffer":   "1.5 KB",
+		"port":     "0x1F90",
+		"ratio":    "75%",
+		"enabled":  "yes",
+		"debug":    "off",
+		"timeout":  "1m30s",
+		"sizes":    "[1KiB, 2kb, 0x10]",
+	})
+	if err != nil {
+		t.Errorf("MergeMap() error = %v", err)
+	}
+
+	err = m.MergeMap(&unitsConfig{}, map[string]string{"max_body": "10%", "enabled": "maybe"})
+	schemaErr, ok := err.(*merger.SchemaError)
+	if !ok {
+		t.Fatalf("MergeMap() error = %v, want a SchemaError", err)
+	}
+	want := []merger.SchemaViolation{
+		{Path: "enabled", Message: "expected type boolean"},
+		{Path: "max_body", Message: "expected type integer"},
+	}
+	if !reflect.DeepEqual(schemaErr.Violations, want) {
+		t.Errorf("MergeMap() violations = %+v, want %+v", schemaErr.Violations, want)
+	}
+}
+
+func TestWithSchema_Invalid(t *testing.T) {
+	err := merger.New(merger.WithSchema([]byte(`{`))).MergeMap(&Student{}, map[string]string{"name": "John"})
+	if err == nil {
+		t.Errorf("MergeMap() error = nil, want an error")
+	}
+}
+
+func TestWithSchema_KeyCodec(t *testing.T) {
+	m := merger.New(merger.WithSchema([]byte(studentSchema)), merger.WithKeyCodec(merger.KeyCodec{Separator: "."}))
+	err := m.MergeMap(&Student{}, map[string]string{"name": "John", "address.country": "CA"})
+
+	schemaErr, ok := err.(*merger.SchemaError)
+	if !ok {
+		t.Fatalf("MergeMap() error = %v, want a SchemaError", err)
+	}
+	want := []merger.SchemaViolation{
+		{Path: "address.city", Message: "required parameter is missing"},
+		{Path: "address.country", Message: "the value is not one of the allowed values"},
+	}
+	if !reflect.DeepEqual(schemaErr.Violations, want) {
+		t.Errorf("MergeMap() violations = %+v, want %+v", schemaErr.Violations, want)
+	}
+}
@@ -0,0 +1,142 @@
+package merger
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Secret is a string that is redacted when it's printed. The fields of this
+// type are secrets like the fields tagged with `merger:"secret"`
+type Secret string
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// String returns the redacted value of the secret
+func (s Secret) String() string {
+	return RedactedValue
+}
+
+// GoString returns the redacted value of the secret
+func (s Secret) GoString() string {
+	return strconv.Quote(RedactedValue)
+}
+
+// Reveal returns the raw value of the secret
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// Reveal prevents the Merger from redacting the secret values, the values of
+// the fields tagged with `merger:"secret"` or of type Secret
+func Reveal() Option {
+	return func(m *Merger) {
+		m.reveal = true
+	}
+}
+
+// isSecret returns true if the field is tagged as secret or the type is Secret
+func isSecret(field reflect.StructField) bool {
+	return hasOption(field, "secret") || isSecretType(field.Type)
+}
+
+func isSecretType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t == secretType
+}
+
+// redact returns a copy of the parameters with the secret values redacted
+func redact(m map[string]string, secrets map[string]bool) map[string]string {
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		if secrets[k] {
+			v = RedactedValue
+		}
+		r[k] = v
+	}
+	return r
+}
+
+// redactValues returns a copy of the typed parameters with the secret values
+// redacted
+func redactValues(m map[string]interface{}, secrets map[string]bool) map[string]interface{} {
+	r := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if secrets[k] {
+			v = RedactedValue
+		}
+		r[k] = v
+	}
+	return r
+}
+
+// secretValues returns the values of the nested map that go to a secret field
+// of the type t. The fields are identified like decode does, by the given tag
+// name or by the mapstructure tag if it's empty
+func secretValues(t reflect.Type, m map[string]interface{}, tagName string) []string {
+	return collectSecretValues(t, m, tagName, false, nil)
+}
+
+func collectSecretValues(t reflect.Type, v interface{}, tagName string, secret bool, values []string) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			var childType reflect.Type
+			childSecret := secret
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Struct:
+				if field, ok := structFieldByKey(t, k, tagName); ok {
+					childType = field.Type
+					childSecret = childSecret || isSecret(field)
+				}
+			case t.Kind() == reflect.Map:
+				childType = t.Elem()
+				childSecret = childSecret || isSecretType(childType)
+			}
+			values = collectSecretValues(childType, child, tagName, childSecret, values)
+		}
+	case []interface{}:
+		if secret {
+			for _, item := range value {
+				values = collectSecretValues(nil, item, tagName, secret, values)
+			}
+		}
+	case []string:
+		if secret {
+			values = append(values, value...)
+		}
+	case string:
+		if secret && len(value) != 0 {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// redactError replaces the quoted secret values in the error message
+func redactError(err error, values []string) error {
+	if err == nil || len(values) == 0 {
+		return err
+	}
+
+	msg := err.Error()
+	redacted := msg
+	for _, v := range values {
+		redacted = strings.Replace(redacted, strconv.Quote(v), strconv.Quote(RedactedValue), -1)
+		redacted = strings.Replace(redacted, "'"+v+"'", "'"+RedactedValue+"'", -1)
+	}
+	if redacted == msg {
+		return err
+	}
+
+	return errors.New(redacted)
+}
@@ -0,0 +1,119 @@
+package merger_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+type Account struct {
+	User     string        `json:"user"`
+	Password merger.Secret `json:"password"`
+	PIN      int           `json:"pin" merger:"secret"`
+	Keys     []string      `json:"keys" merger:"secret"`
+}
+
+func TestSecret(t *testing.T) {
+	s := merger.Secret("s3cr3t")
+
+	for _, format := range []string{"%v", "%s", "%q", "%+v", "%#v"} {
+		if got := fmt.Sprintf(format, s); strings.Contains(got, "s3cr3t") {
+			t.Errorf("Sprintf(%q) = %s, the secret is not redacted", format, got)
+		}
+	}
+	if got := fmt.Sprintf("%+v", Account{Password: s}); strings.Contains(got, "s3cr3t") {
+		t.Errorf("Sprintf(%%+v) = %s, the secret is not redacted", got)
+	}
+	if got := s.Reveal(); got != "s3cr3t" {
+		t.Errorf("Reveal() = %s, want s3cr3t", got)
+	}
+}
+
+func TestTransformToMap_Secrets(t *testing.T) {
+	account := &Account{
+		User:     "admin",
+		Password: "s3cr3t",
+		PIN:      1234,
+		Keys:     []string{"k1", "k2"},
+	}
+
+	tests := []struct {
+		name   string
+		merger *merger.Merger
+		want   map[string]string
+	}{
+		{name: "redacted",
+			merger: merger.New(),
+			want: map[string]string{
+				"user":     "admin",
+				"password": merger.RedactedValue,
+				"pin":      merger.RedactedValue,
+				"keys":     merger.RedactedValue,
+			},
+		},
+		{name: "revealed",
+			merger: merger.New(merger.Reveal()),
+			want: map[string]string{
+				"user":     "admin",
+				"password": "s3cr3t",
+				"pin":      "1234",
+				"keys":     "[k1, k2]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.merger.TransformToMap(account)
+			if err != nil {
+				t.Fatalf("TransformToMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransformToMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, _ := merger.TransformToMap(account); got["password"] != merger.RedactedValue {
+		t.Errorf("TransformToMap() = %v, the password is not redacted", got)
+	}
+}
+
+func TestDiff_Secrets(t *testing.T) {
+	a := &Account{User: "admin", Password: "s3cr3t"}
+	b := &Account{User: "admin", Password: "pa55w0rd"}
+
+	want := []merger.Change{
+		{Path: "password", Old: merger.RedactedValue, New: merger.RedactedValue, Kind: merger.Modified},
+	}
+	if got := merger.Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	want = []merger.Change{
+		{Path: "password", Old: "s3cr3t", New: "pa55w0rd", Kind: merger.Modified},
+	}
+	if got := merger.New(merger.Reveal()).Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeMap_SecretErrors(t *testing.T) {
+	err := merger.MergeMap(&Account{}, map[string]string{"User": "admin", "PIN": "my-pin-1234"})
+	if err == nil {
+		t.Fatal("MergeMap() error = nil, want an error")
+	}
+	if strings.Contains(err.Error(), "my-pin-1234") {
+		t.Errorf("MergeMap() error = %v, the secret is not redacted", err)
+	}
+
+	err = merger.ApplyMergePatch(&Account{}, []byte(`{"pin": "my-pin-1234"}`))
+	if err == nil {
+		t.Fatal("ApplyMergePatch() error = nil, want an error")
+	}
+	if strings.Contains(err.Error(), "my-pin-1234") {
+		t.Errorf("ApplyMergePatch() error = %v, the secret is not redacted", err)
+	}
+}
@@ -0,0 +1,175 @@
+package merger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Source is a source map with its provenance
+type Source struct {
+	// Name identifies the source in the Metadata, i.e. "env" or the file name
+	Name string
+	// Path is the file the values come from, if any. The relative paths of the
+	// fields tagged with `merger:"path"` are relative to its directory
+	Path string
+	// Values are the parameters, like the source maps of MergeMap
+	Values map[string]string
+	// Document is a nested document, like the ones returned by Unmarshal. Its
+	// values keep their types, they are not parsed like the Values, and they
+	// have lower priority than the Values
+	Document map[string]interface{}
+}
+
+// dir returns the directory to resolve the relative paths, empty if the
+// source is not a file
+func (s Source) dir() string {
+	if len(s.Path) == 0 {
+		return ""
+	}
+	return filepath.Dir(s.Path)
+}
+
+// MergeSources merges the given sources into the dst structure like MergeMap,
+// the first source has the highest priority. The values of the fields tagged
+// with `merger:"path"` are resolved: the `~` is expanded to the home
+// directory and a relative path is joined to the directory of the source
+// file. With `merger:"path,exists"` the path has to exist
+func MergeSources(dst interface{}, sources ...Source) error {
+	return defaultMerger.MergeSources(dst, sources...)
+}
+
+// MergeSources merges the given sources into the dst structure like MergeMap,
+// the first source has the highest priority. The Metadata has the name of the
+// source of every parameter
+func (m *Merger) MergeSources(dst interface{}, sources ...Source) error {
+	m.metadata.reset(m.keyCodec, reflect.TypeOf(dst))
+
+	if err := m.checkSources(sources); err != nil {
+		return err
+	}
+
+	for i := range sources {
+		if err := m.mergeSource(dst, sources[len(sources)-i-1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformSource returns the nested map of the source, the Values are
+// transformed like in TransformMap and merged into a copy of the Document
+func (m *Merger) transformSource(src Source) map[string]interface{} {
+	tm := m.TransformMap(src.Values)
+	if src.Document == nil {
+		return tm
+	}
+	return mergeTwoMaps(copyMap(src.Document), tm, true)
+}
+
+// resolvePaths resolves, in the nested map, the values of the fields of the
+// type t tagged with `merger:"path"`. The relative paths are joined to dir, if
+// it's not empty. The map keys are the names used to decode the fields, like
+// the ones returned by normalizeKeys, the errors name the parameters with the
+// codec
+func resolvePaths(t reflect.Type, m map[string]interface{}, dir string, parent []string, codec KeyCodec) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for k, v := range m {
+		path := append(append([]string{}, parent...), k)
+
+		var field reflect.StructField
+		switch t.Kind() {
+		case reflect.Map:
+			field = reflect.StructField{Type: t.Elem()}
+		case reflect.Struct:
+			var ok bool
+			if field, ok = structFieldByKey(t, k, ""); !ok {
+				continue
+			}
+		default:
+			return nil
+		}
+
+		if child, ok := v.(map[string]interface{}); ok {
+			if err := resolvePaths(field.Type, child, dir, path, codec); err != nil {
+				return err
+			}
+			continue
+		}
+		if !hasOption(field, "path") {
+			continue
+		}
+
+		key := strings.ToLower(codec.join(path))
+		resolved, err := resolvePathValue(v, dir, hasOption(field, "exists"), key)
+		if err != nil {
+			return err
+		}
+		m[k] = resolved
+	}
+
+	return nil
+}
+
+// resolvePathValue resolves the path, or the list of paths, of the parameter
+// key
+func resolvePathValue(v interface{}, dir string, exists bool, key string) (interface{}, error) {
+	switch value := v.(type) {
+	case string:
+		return resolvePath(value, dir, exists, key)
+	case []string:
+		paths := make([]string, 0, len(value))
+		for _, p := range value {
+			resolved, err := resolvePath(p, dir, exists, key)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, resolved)
+		}
+		return paths, nil
+	case []interface{}:
+		paths := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			resolved, err := resolvePathValue(item, dir, exists, key)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, resolved)
+		}
+		return paths, nil
+	default:
+		return v, nil
+	}
+}
+
+// resolvePath expands the `~` to the home directory and joins the relative
+// path to dir. The empty paths are not resolved
+func resolvePath(p, dir string, exists bool, key string) (string, error) {
+	if len(p) == 0 {
+		return p, nil
+	}
+
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand the path %q of %q: %s", p, key, err)
+		}
+		p = filepath.Join(home, p[1:])
+	}
+	if !filepath.IsAbs(p) && len(dir) != 0 {
+		p = filepath.Join(dir, p)
+	}
+
+	if exists {
+		if _, err := os.Stat(p); err != nil {
+			return "", fmt.Errorf("the path %q of %q does not exist", p, key)
+		}
+	}
+	return p, nil
+}
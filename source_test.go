@@ -0,0 +1,120 @@
+package merger_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+type sourceTLS struct {
+	CA   string `json:"ca" merger:"path"`
+	Cert string `json:"cert" merger:"path,exists"`
+}
+
+type sourceConfig struct {
+	Name    string               `json:"name"`
+	Data    string               `json:"data" merger:"path"`
+	Plugins []string             `json:"plugins" merger:"path"`
+	TLS     sourceTLS            `json:"tls"`
+	Mounts  map[string]sourceTLS `json:"mounts"`
+}
+
+func TestMergeSources(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(cert, []byte("cert"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		sources []merger.Source
+		want    sourceConfig
+		wantErr string
+	}{
+		{name: "relative to the source file",
+			sources: []merger.Source{
+				{Name: "db", Path: "/etc/app/conf.d/db.yaml", Values: map[string]string{
+					"data":            "data",
+					"plugins":         "[a.so, /usr/lib/b.so]",
+					"tls__ca":         "certs/ca.pem",
+					"mounts__web__ca": "../web/ca.pem",
+				}},
+			},
+			want: sourceConfig{
+				Data:    "/etc/app/conf.d/data",
+				Plugins: []string{"/etc/app/conf.d/a.so", "/usr/lib/b.so"},
+				TLS:     sourceTLS{CA: "/etc/app/conf.d/certs/ca.pem"},
+				Mounts:  map[string]sourceTLS{"web": {CA: "/etc/app/web/ca.pem"}},
+			},
+		},
+		{name: "home directory",
+			sources: []merger.Source{
+				{Name: "env", Values: map[string]string{"data": "~/data", "tls__ca": "certs/ca.pem", "name": "~"}},
+			},
+			want: sourceConfig{
+				Name: "~",
+				Data: filepath.Join(home, "data"),
+				TLS:  sourceTLS{CA: "certs/ca.pem"},
+			},
+		},
+		{name: "priority",
+			sources: []merger.Source{
+				{Name: "env", Values: map[string]string{"tls__ca": "/ca.pem"}},
+				{Name: "file", Path: filepath.Join(dir, "app.yaml"), Values: map[string]string{"tls__ca": "ca.pem", "tls__cert": "cert.pem"}},
+			},
+			want: sourceConfig{
+				TLS: sourceTLS{CA: "/ca.pem", Cert: cert},
+			},
+		},
+		{name: "missing file",
+			sources: []merger.Source{
+				{Name: "file", Path: filepath.Join(dir, "app.yaml"), Values: map[string]string{"tls__cert": "missing.pem"}},
+			},
+			wantErr: `of "tls__cert" does not exist`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sourceConfig{}
+			err := merger.MergeSources(&got, tt.sources...)
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MergeSources() error = %v, want an error with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeSources() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeSources() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSourcesMetadata(t *testing.T) {
+	md := &merger.Metadata{}
+	m := merger.New(merger.WithMetadata(md))
+
+	err := m.MergeSources(&sourceConfig{},
+		merger.Source{Name: "env", Values: map[string]string{"name": "api"}},
+		merger.Source{Name: "file", Path: "/etc/app.yaml", Values: map[string]string{"name": "app", "data": "data", "tls__ca": "!unset"}},
+	)
+	if err != nil {
+		t.Fatalf("MergeSources() error = %v", err)
+	}
+
+	want := map[string]string{"name": "env", "data": "file"}
+	if !reflect.DeepEqual(md.Sources, want) {
+		t.Errorf("Metadata.Sources = %v, want %v", md.Sources, want)
+	}
+}
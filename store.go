@@ -0,0 +1,150 @@
+package merger
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds a merged configuration to share across goroutines. Every value
+// in the store is an immutable snapshot: Update modifies a deep copy of the
+// current snapshot and swaps it atomically, so the readers never see a
+// partially modified configuration. The values returned by Get share their
+// maps and slices with the snapshot, they must not be modified.
+type Store[T any] struct {
+	value atomic.Value
+
+	mu          sync.Mutex
+	subscribers []func(old, new T)
+}
+
+// NewStore creates a Store with the given configuration, if the
+// configuration implements Validator it has to be valid. T can't be a pointer,
+// the snapshots would be shared with every caller of Get
+func NewStore[T any](v T) (*Store[T], error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() == reflect.Ptr {
+		return nil, fmt.Errorf("invalid configuration type %s, the Store holds values, not pointers", t)
+	}
+
+	v = copyOf(v)
+	if err := validate(&v); err != nil {
+		return nil, err
+	}
+
+	s := &Store[T]{}
+	s.value.Store(&v)
+
+	return s, nil
+}
+
+// Get returns the current configuration
+func (s *Store[T]) Get() T {
+	return *s.value.Load().(*T)
+}
+
+// Set replaces the configuration with the given one, if it's valid
+func (s *Store[T]) Set(v T) error {
+	v = copyOf(v)
+	return s.Update(func(cfg *T) {
+		*cfg = v
+	})
+}
+
+// Update modifies a copy of the current configuration with the given
+// function. The copy replaces the configuration only if it's valid, then the
+// subscribers are notified. The subscribers may call Update or Subscribe.
+func (s *Store[T]) Update(fn func(*T)) error {
+	s.mu.Lock()
+
+	oldValue := s.value.Load().(*T)
+
+	newValue := new(T)
+	*newValue = copyOf(*oldValue)
+	fn(newValue)
+
+	if err := validate(newValue); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.value.Store(newValue)
+
+	subscribers := make([]func(old, new T), len(s.subscribers))
+	copy(subscribers, s.subscribers)
+	s.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(*oldValue, *newValue)
+	}
+
+	return nil
+}
+
+// Subscribe registers a function to call with the old and new configuration
+// every time the configuration is updated
+func (s *Store[T]) Subscribe(fn func(old, new T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers = append(s.subscribers, fn)
+}
+
+func copyOf[T any](v T) T {
+	return *deepCopy(reflect.ValueOf(&v)).Interface().(*T)
+}
+
+// deepCopy returns a copy of the given value that do not share any pointer,
+// map or slice with the original. Unexported fields are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	c := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.New(v.Type().Elem()))
+		c.Elem().Set(deepCopy(v.Elem()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(deepCopy(v.Elem()))
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i)))
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		for _, key := range v.MapKeys() {
+			c.SetMapIndex(key, deepCopy(v.MapIndex(key)))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return c
+		}
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	default:
+		c.Set(v)
+	}
+
+	return c
+}
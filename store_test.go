@@ -0,0 +1,176 @@
+package merger_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func TestNewStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       ServerConfig
+		wantErr bool
+	}{
+		{name: "valid",
+			v:       ServerConfig{Host: "localhost", Port: 8080},
+			wantErr: false,
+		},
+		{name: "invalid",
+			v:       ServerConfig{Host: "localhost"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := merger.NewStore(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := s.Get(); !reflect.DeepEqual(got, tt.v) {
+				t.Errorf("Get() = %+v, want %+v", got, tt.v)
+			}
+		})
+	}
+}
+
+func TestNewStore_Pointer(t *testing.T) {
+	if _, err := merger.NewStore(&ServerConfig{Host: "localhost", Port: -1}); err == nil {
+		t.Errorf("NewStore() error = nil, want an error")
+	}
+}
+
+func TestStore_Update(t *testing.T) {
+	initial := Person{
+		Name:   "Pepe",
+		Age:    30,
+		Phones: map[string]Phone{"home": Phone{Number: "858-123-4567"}},
+	}
+	s, err := merger.NewStore(initial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotOld, gotNew Person
+	s.Subscribe(func(old, new Person) {
+		gotOld, gotNew = old, new
+	})
+
+	before := s.Get()
+	err = s.Update(func(p *Person) {
+		p.Age = 31
+		p.Phones["mobile"] = Phone{Number: "858-987-6543"}
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if len(before.Phones) != 1 || before.Age != 30 {
+		t.Errorf("Update() modified the previous snapshot: %+v", before)
+	}
+	if len(initial.Phones) != 1 {
+		t.Errorf("Update() modified the initial value: %+v", initial)
+	}
+
+	want := Person{
+		Name: "Pepe",
+		Age:  31,
+		Phones: map[string]Phone{
+			"home":   Phone{Number: "858-123-4567"},
+			"mobile": Phone{Number: "858-987-6543"},
+		},
+	}
+	if got := s.Get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(gotOld, before) || !reflect.DeepEqual(gotNew, want) {
+		t.Errorf("Subscribe() got old = %+v, new = %+v", gotOld, gotNew)
+	}
+}
+
+func TestStore_UpdateInvalid(t *testing.T) {
+	s, err := merger.NewStore(ServerConfig{Host: "localhost", Port: 8080})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notified := false
+	s.Subscribe(func(old, new ServerConfig) {
+		notified = true
+	})
+
+	if err := s.Update(func(c *ServerConfig) { c.Port = 0 }); err == nil {
+		t.Errorf("Update() error = nil, want an error")
+	}
+	if err := s.Set(ServerConfig{Host: "example.com"}); err == nil {
+		t.Errorf("Set() error = nil, want an error")
+	}
+
+	want := ServerConfig{Host: "localhost", Port: 8080}
+	if got := s.Get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	if notified {
+		t.Errorf("invalid updates notified the subscribers")
+	}
+}
+
+func TestStore_SubscriberUpdate(t *testing.T) {
+	s, err := merger.NewStore(ServerConfig{Host: "localhost", Port: 8080})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscriber moves any update of the host to the port 443
+	s.Subscribe(func(old, new ServerConfig) {
+		if old.Host != new.Host && new.Port != 443 {
+			if err := s.Update(func(c *ServerConfig) { c.Port = 443 }); err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}
+	})
+
+	if err := s.Update(func(c *ServerConfig) { c.Host = "example.com" }); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	want := ServerConfig{Host: "example.com", Port: 443}
+	if got := s.Get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	s, err := merger.NewStore(ServerConfig{Host: "localhost", Port: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.Update(func(c *ServerConfig) {
+				c.Port++
+				c.Host = "example.com"
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			if c := s.Get(); c.Port > 1 && c.Host != "example.com" {
+				t.Errorf("Get() returned a partial update: %+v", c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := s.Get().Port; got != 11 {
+		t.Errorf("Get().Port = %d, want 11", got)
+	}
+}
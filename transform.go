@@ -4,15 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
-const defaultTagName = "json"
+const (
+	defaultTagName = "json"
+	mergerTagName  = "merger"
+)
 
-// TransformMap transform a map of string values to interface{} values
+// TransformMap transform a map of string values to interface{} values. The
+// keys are split in fields by FieldSeparator. If several keys set the same
+// parameter, i.e. `address={"city":"LA"}` and `address__city=SF`, the more
+// specific key wins, the one with more fields
 func TransformMap(srcMap map[string]string) map[string]interface{} {
+	return defaultMerger.TransformMap(srcMap)
+}
+
+// TransformMap transform a map of string values to interface{} values. The
+// keys are split in fields and map keys with the KeyCodec of the Merger
+func (m *Merger) TransformMap(srcMap map[string]string) map[string]interface{} {
+	return transformMap(srcMap, m.keyCodec)
+}
+
+func transformMap(srcMap map[string]string, codec KeyCodec) map[string]interface{} {
 	m := make(map[string]interface{}, 0)
-	for k, v := range srcMap {
+	for _, k := range sortedBySpecificity(srcMap, codec) {
+		v := srcMap[k]
 		var i interface{}
 		switch {
 		case isSlice(v):
@@ -23,11 +41,14 @@ func TransformMap(srcMap map[string]string) map[string]interface{} {
 			i = v
 		}
 
-		if isStructField(k) {
-			m = transformToStructField(m, k, i)
+		path := codec.path(k)
+
+		if len(path) > 1 {
+			m = transformToStructField(m, path, i)
 		} else {
-			if m[k] != nil && isMap(i) {
-				m[k] = mergeTwoMaps(m[k].(map[string]interface{}), i.(map[string]interface{}), false)
+			k = path[0]
+			if isMap(m[k]) && isMap(i) {
+				m[k] = mergeTwoMaps(m[k].(map[string]interface{}), i.(map[string]interface{}), true)
 			} else {
 				m[k] = i
 			}
@@ -37,6 +58,24 @@ func TransformMap(srcMap map[string]string) map[string]interface{} {
 	return m
 }
 
+// sortedBySpecificity returns the keys of the map sorted by the number of
+// fields and then alphabetically, so the more specific keys are applied last
+func sortedBySpecificity[V any](srcMap map[string]V, codec KeyCodec) []string {
+	keys := make([]string, 0, len(srcMap))
+	depth := make(map[string]int, len(srcMap))
+	for k := range srcMap {
+		keys = append(keys, k)
+		depth[k] = len(codec.decode(k))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if depth[keys[i]] != depth[keys[j]] {
+			return depth[keys[i]] < depth[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
 func isSlice(v string) bool {
 	return (strings.Contains(v, ",") || (strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]"))) && !isJSONStruct(v)
 }
@@ -52,20 +91,14 @@ func transformToSlice(v string) []string {
 	return values
 }
 
-func isStructField(k string) bool {
-	return strings.Contains(k, FieldSeparator)
-}
-func transformToStructField(m map[string]interface{}, k string, v interface{}) map[string]interface{} {
-	if isStructField(k) {
-		keys := strings.Split(k, FieldSeparator)
-		k0 := keys[0]
-		r := strings.Join(keys[1:], FieldSeparator)
-
-		if _, ok := m[k0]; !ok {
-			m[k0] = make(map[string]interface{}, 0)
+func transformToStructField(m map[string]interface{}, path []string, v interface{}) map[string]interface{} {
+	k := path[0]
+	if len(path) > 1 {
+		if _, ok := m[k].(map[string]interface{}); !ok {
+			m[k] = make(map[string]interface{}, 0)
 		}
 
-		m[k0] = transformToStructField(m[k0].(map[string]interface{}), r, v)
+		m[k] = transformToStructField(m[k].(map[string]interface{}), path[1:], v)
 		return m
 	}
 
@@ -74,8 +107,8 @@ func transformToStructField(m map[string]interface{}, k string, v interface{}) m
 		return m
 	}
 
-	if m[k] != nil && isMap(v) {
-		m[k] = mergeTwoMaps(m[k].(map[string]interface{}), v.(map[string]interface{}), false)
+	if isMap(m[k]) && isMap(v) {
+		m[k] = mergeTwoMaps(m[k].(map[string]interface{}), v.(map[string]interface{}), true)
 		return m
 	}
 
@@ -94,6 +127,9 @@ func transformJSONToStruct(v string) map[string]interface{} {
 }
 
 func isMap(v interface{}) bool {
+	if v == nil {
+		return false
+	}
 	t := reflect.TypeOf(v).String()
 	return t == "map[string]interface {}" || t == "map[string]string"
 }
@@ -122,90 +158,180 @@ func mergeTwoMaps(dstMap, srcMap map[string]interface{}, overwrite bool) map[str
 
 // TransformToMap returns the given interface (has to be a struct) as a set of
 // variables + values map. Useful to get the environment variables or parameters
-// of a given struct before merge it with other struct
+// of a given struct before merge it with other struct. The values of the secret
+// fields are redacted. The field names are in lower case, see WithKeyCase,
+// and the map keys keep the case but the characters that can't be in a
+// parameter name are percent-encoded, i.e. "Computer Science" is
+// "Computer%20Science", so MergeMap reproduces the same map keys
 func TransformToMap(v interface{}, tagNames ...string) (map[string]string, error) {
-	m := map[string]string{}
+	return defaultMerger.TransformToMap(v, tagNames...)
+}
+
+// TransformToMap returns the given interface (has to be a struct) as a set of
+// variables + values map. The values of the secret fields are redacted unless
+// the Merger was created with the Reveal option
+func (m *Merger) TransformToMap(v interface{}, tagNames ...string) (map[string]string, error) {
+	f, err := m.parse(v, tagNames, false)
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	if m.reveal {
+		return f.m, nil
+	}
+	return redact(f.m, f.secrets), nil
+}
+
+// parse returns the flattener with the parameters of v, a pointer to a
+// struct. If typed, the flattener also collects the values with their types
+func (m *Merger) parse(v interface{}, tagNames []string, typed bool) (*flattener, error) {
+	if len(tagNames) == 0 {
+		tagNames = []string{defaultTagName}
+	}
+
+	f := newFlattener(tagNames)
+	f.codec = m.keyCodec
+	f.keyCase = m.keyCase
+	f.types = m.types
+	if typed {
+		f.values = map[string]interface{}{}
+	}
 
 	if v == nil {
-		return m, nil
+		return f, nil
 	}
 
 	ptrRef := reflect.ValueOf(v)
 	if ptrRef.Kind() != reflect.Ptr {
-		return m, fmt.Errorf("invalid value, it's not a struct pointer, it's a %s. %v", ptrRef.Kind().String(), ptrRef)
+		return nil, fmt.Errorf("invalid value, it's not a struct pointer, it's a %s. %v", ptrRef.Kind().String(), ptrRef)
 	}
 	ref := ptrRef.Elem()
 	if ref.Kind() != reflect.Struct {
-		return m, fmt.Errorf("invalid value, it's not a struct, it's a %s. %v", ref.Kind().String(), ref)
+		return nil, fmt.Errorf("invalid value, it's not a struct, it's a %s. %v", ref.Kind().String(), ref)
 	}
 
-	if len(tagNames) == 0 {
-		tagNames = []string{defaultTagName}
-	}
+	f.parseStruct(nil, ref, false)
+
+	return f, f.err
+}
 
-	return parseStruct("", ref, m, tagNames), nil
+// flattener walks a struct collecting the parameters and their values. The
+// parameters are named with the KeyCodec, DefaultKeyCodec by default, and the
+// map keys are escaped with mapKey, to be decoded back by TransformMap by
+// default. If values is not nil, it also collects the values with their types
+type flattener struct {
+	tagNames []string
+	codec    KeyCodec
+	keyCase  KeyCase
+	types    *TypeRegistry
+	mapKey   func(key string) string
+	m        map[string]string
+	values   map[string]interface{}
+	secrets  map[string]bool
+	// err is the first error encoding a value
+	err error
 }
 
-func parseStruct(parent string, val reflect.Value, m map[string]string, tagNames []string) map[string]string {
+func newFlattener(tagNames []string) *flattener {
+	f := &flattener{
+		tagNames: tagNames,
+		codec:    DefaultKeyCodec,
+		types:    DefaultTypeRegistry,
+		m:        map[string]string{},
+		secrets:  map[string]bool{},
+	}
+	f.mapKey = func(key string) string {
+		return f.codec.escapeMapKey(key)
+	}
+	return f
+}
+
+func (f *flattener) parseStruct(parent []keySegment, val reflect.Value, secret bool) {
 	valType := val.Type()
 	for i := 0; i < valType.NumField(); i++ {
 		refTypeField := valType.Field(i)
-		name, ignore := getName(refTypeField, tagNames)
+		name, ignore := getName(refTypeField, f.tagNames)
 		if ignore {
 			continue
 		}
 
-		if len(parent) != 0 {
-			name = parent + FieldSeparator + name
-		}
+		// Because all the field names are lower case. Case does not matter, but
+		// it does in the map keys
+		path := append(append([]keySegment{}, parent...), keySegment{name: f.keyCase.apply(name)})
 
-		// Because all the names are lower case. Case does not matter
-		name = strings.ToLower(name)
 		valField := val.Field(i)
-		m = appendTo(name, m, valField, tagNames)
+		f.appendTo(path, valField, secret || isSecret(refTypeField))
 	}
-
-	return m
 }
 
 func getName(field reflect.StructField, tagNames []string) (name string, ignore bool) {
 	// Try the given keys, in order of importance ...
 	for _, tagName := range tagNames {
-		var ok bool
-		if name, ok = field.Tag.Lookup(tagName); ok && len(name) != 0 && name != "-" {
-			// if this tag is not found or contain an empty or `-` value, try the next tag
+		tag, ok := field.Tag.Lookup(tagName)
+		if tag == "-" {
+			// If the tag is `-` it will be ignored
+			return "", true
+		}
+		// Ignore the tag options, i.e. `json:"name,omitempty"`. Like in
+		// encoding/json, `-,` is the name "-"
+		name = strings.SplitN(tag, ",", 2)[0]
+		if ok && len(name) != 0 {
+			// if this tag is not found or contain an empty value, try the next tag
 			// otherwise, end the loop bc the name have been found
 			return name, false
 		}
-		if name == "-" {
-			// If the tag name is `-` it will be ignored
-			return "", true
-		}
 	}
 
 	// If any tag is found, or they are empty and are not `-`, return the field name
 	return field.Name, false
 }
 
-func appendTo(name string, m map[string]string, v reflect.Value, tagNames []string) map[string]string {
+// hasOption returns true if the field has the given option in the `merger`
+// tag, i.e. `merger:"secret"`
+func hasOption(field reflect.StructField, option string) bool {
+	for _, opt := range strings.Split(field.Tag.Get(mergerTagName), ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *flattener) appendTo(path []keySegment, v reflect.Value, secret bool) {
 	if !v.CanInterface() {
-		return m
+		return
 	}
 
 	val := reflect.ValueOf(v.Interface())
+	if val.IsValid() {
+		// The registered types and the TextMarshaler are parameters even if
+		// they are structs or slices
+		s, ok, err := f.types.encode(val)
+		if err != nil && f.err == nil {
+			f.err = fmt.Errorf("cannot encode %q: %s", f.codec.encode(path), err)
+		}
+		if ok {
+			f.set(path, s, nativeValue(val), secret || val.Type() == secretType)
+			return
+		}
+	}
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
+	if !val.IsValid() {
+		return
+	}
+
+	secret = secret || val.Type() == secretType
 
 	switch val.Kind() {
 	case reflect.Struct:
-		m = parseStruct(name, val, m, tagNames)
+		f.parseStruct(path, val, secret)
 	case reflect.Map:
 		for _, key := range val.MapKeys() {
-			keyStr := fmt.Sprintf("%v", key.Interface())
-			keyStr = strings.Replace(keyStr, " ", "_", -1)
-			n := name + FieldSeparator + keyStr
-			m = appendTo(n, m, val.MapIndex(key), tagNames)
+			keyStr := f.mapKey(fmt.Sprintf("%v", key.Interface()))
+			keyPath := append(append([]keySegment{}, path...), keySegment{name: keyStr, mapKey: true})
+			f.appendTo(keyPath, val.MapIndex(key), secret)
 		}
 	case reflect.Slice, reflect.Array:
 		switch val.Type().Elem().Kind() {
@@ -217,17 +343,49 @@ func appendTo(name string, m map[string]string, v reflect.Value, tagNames []stri
 			if len := val.Len(); len > 0 {
 				var i int
 				for i = 0; i < len-1; i++ {
-					list = list + fmt.Sprintf("%v", val.Index(i).Interface()) + ", "
+					list = list + rawString(val.Index(i)) + ", "
 				}
-				list = list + fmt.Sprintf("%v", val.Index(i).Interface())
+				list = list + rawString(val.Index(i))
 			}
 			list = list + "]"
-			m[name] = list
+			f.set(path, list, nativeValue(val), secret)
 		}
 	default:
-		value := val.Interface()
-		m[name] = fmt.Sprintf("%v", value)
+		f.set(path, rawString(val), nativeValue(val), secret)
 	}
+}
 
-	return m
+// nativeValue returns the value to collect with its type. The slices are
+// copied and the Secret values are returned as strings
+func nativeValue(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == secretType:
+		return v.String()
+	case v.Kind() == reflect.Slice:
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c.Interface()
+	default:
+		return v.Interface()
+	}
+}
+
+// rawString returns the value as a string, the string types are returned as
+// they are to not use their String method, i.e. Secret
+func rawString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func (f *flattener) set(path []keySegment, value string, typed interface{}, secret bool) {
+	name := f.codec.encode(path)
+	f.m[name] = value
+	if f.values != nil {
+		f.values[name] = typed
+	}
+	if secret {
+		f.secrets[name] = true
+	}
 }
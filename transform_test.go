@@ -160,15 +160,15 @@ var movie01 = Movie{
 	},
 }
 var movie01Map = map[string]string{
-	"title":                             "Seven Samurai",
-	"year":                              "1954",
-	"actors__kikuchiyo__full_name":      "Toshirô Mifune",
-	"actors__kikuchiyo__age":            "0",
-	"actors__kambei_shimada__full_name": "Takashi Shimura",
-	"actors__kambei_shimada__age":       "0",
-	"genres":                            "[Adventure, Drama]",
-	"release_year_per_country__JP":      "1954",
-	"release_year_per_country__US":      "1956",
+	"title":                               "Seven Samurai",
+	"year":                                "1954",
+	"actors__Kikuchiyo__full_name":        "Toshirô Mifune",
+	"actors__Kikuchiyo__age":              "0",
+	"actors__Kambei%20Shimada__full_name": "Takashi Shimura",
+	"actors__Kambei%20Shimada__age":       "0",
+	"genres":                              "[Adventure, Drama]",
+	"release_year_per_country__JP":        "1954",
+	"release_year_per_country__US":        "1956",
 }
 
 var movie02 = Movie{
@@ -194,15 +194,15 @@ var movie02 = Movie{
 	},
 }
 var movie02Map = map[string]string{
-	"title":                                "The Godfather",
-	"year":                                 "1972",
-	"actors__don_vito_corleone__full_name": "Marlon Brando",
-	"actors__don_vito_corleone__age":       "0",
-	"actors__michael_corleone__full_name":  "Al Pacino",
-	"actors__michael_corleone__age":        "79",
-	"genres":                               "[Crime, Drama]",
-	"release_year_per_country__HK":         "1973",
-	"release_year_per_country__US":         "1972",
+	"title": "The Godfather",
+	"year":  "1972",
+	"actors__Don%20Vito%20Corleone__full_name": "Marlon Brando",
+	"actors__Don%20Vito%20Corleone__age":       "0",
+	"actors__Michael%20Corleone__full_name":    "Al Pacino",
+	"actors__Michael%20Corleone__age":          "79",
+	"genres":                                   "[Crime, Drama]",
+	"release_year_per_country__HK":             "1973",
+	"release_year_per_country__US":             "1972",
 }
 
 func TestTransformToMap(t *testing.T) {
@@ -325,6 +325,27 @@ func TestTransformToMap(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{name: "tag options",
+			v: &struct {
+				Name    string `json:"name,omitempty"`
+				Port    int    `json:",omitempty" yaml:"listen_port"`
+				Secret  string `json:"-,"`
+				Default string `json:",string"`
+			}{
+				Name:    "api",
+				Port:    8080,
+				Secret:  "s3cr3t",
+				Default: "d",
+			},
+			tags: []string{"json", "yaml"},
+			want: map[string]string{
+				"name":        "api",
+				"listen_port": "8080",
+				"-":           "s3cr3t",
+				"default":     "d",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -340,3 +361,50 @@ func TestTransformToMap(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformToMapRoundTrip(t *testing.T) {
+	keys := []string{
+		"Computer Science",
+		"a__b",
+		"_leading",
+		"trailing_",
+		"a___b",
+		"snake_case",
+		"Ünïcödé 日本",
+		"100%",
+		"a=b",
+		"tab\tnew\nline",
+	}
+
+	tests := []struct {
+		name  string
+		codec merger.KeyCodec
+	}{
+		{name: "default", codec: merger.KeyCodec{}},
+		{name: "single char separator", codec: merger.KeyCodec{Separator: "_"}},
+		{name: "escape", codec: merger.KeyCodec{Separator: ".", Escape: `\`}},
+		{name: "brackets", codec: merger.KeyCodec{Separator: ".", Brackets: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := Student{Grades: map[string]Grade{}}
+			for i, k := range keys {
+				want.Grades[k] = Grade{Teacher: k, Number: float32(i)}
+			}
+
+			m := merger.New(merger.WithKeyCodec(tt.codec), merger.Reveal())
+			params, err := m.TransformToMap(&want)
+			if err != nil {
+				t.Fatalf("TransformToMap() error = %v", err)
+			}
+
+			var got Student
+			if err := m.MergeMap(&got, params); err != nil {
+				t.Fatalf("MergeMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Grades, want.Grades) {
+				t.Errorf("MergeMap(TransformToMap()) = %v, want %v. Parameters: %v", got.Grades, want.Grades, params)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package merger
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// DecodeFunc returns the value, of the registered type, of a parameter
+type DecodeFunc func(s string) (interface{}, error)
+
+// EncodeFunc returns the parameter value of a value of the registered type
+type EncodeFunc func(v interface{}) (string, error)
+
+type typeCodec struct {
+	decode DecodeFunc
+	encode EncodeFunc
+}
+
+// TypeRegistry has the functions to decode the parameter values to a type, in
+// MergeMap, and to encode the values of that type, in TransformToMap. The
+// types implementing encoding.TextUnmarshaler and encoding.TextMarshaler don't
+// need to be registered
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]typeCodec
+}
+
+// DefaultTypeRegistry is the TypeRegistry used by default. It has the types
+// time.Duration, time.Time (in RFC 3339), net.IP, *url.URL, *regexp.Regexp
+// and *big.Int
+var DefaultTypeRegistry = NewTypeRegistry()
+
+// NewTypeRegistry creates a TypeRegistry with the types of
+// DefaultTypeRegistry
+func NewTypeRegistry() *TypeRegistry {
+	r := &TypeRegistry{types: map[reflect.Type]typeCodec{}}
+
+	r.Register(time.Duration(0), func(s string) (interface{}, error) {
+		// A number without unit is in nanoseconds, like a weakly typed int64
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Duration(n), nil
+		}
+		return time.ParseDuration(s)
+	}, func(v interface{}) (string, error) {
+		return v.(time.Duration).String(), nil
+	})
+	r.Register(time.Time{}, func(s string) (interface{}, error) {
+		return time.Parse(time.RFC3339Nano, s)
+	}, func(v interface{}) (string, error) {
+		return v.(time.Time).Format(time.RFC3339Nano), nil
+	})
+	r.Register(net.IP{}, func(s string) (interface{}, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", s)
+		}
+		return ip, nil
+	}, func(v interface{}) (string, error) {
+		return v.(net.IP).String(), nil
+	})
+	r.Register(&url.URL{}, func(s string) (interface{}, error) {
+		return url.Parse(s)
+	}, func(v interface{}) (string, error) {
+		return v.(*url.URL).String(), nil
+	})
+	r.Register(&regexp.Regexp{}, func(s string) (interface{}, error) {
+		return regexp.Compile(s)
+	}, func(v interface{}) (string, error) {
+		return v.(*regexp.Regexp).String(), nil
+	})
+	r.Register(&big.Int{}, func(s string) (interface{}, error) {
+		n, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return n, nil
+	}, func(v interface{}) (string, error) {
+		return v.(*big.Int).String(), nil
+	})
+
+	return r
+}
+
+// Register registers the functions to decode and encode the type of the
+// given value, i.e. time.Duration(0) or &url.URL{}. The value returned by
+// decode and the value received by encode are of that type. The fields of the
+// type, and of a pointer to the type, use these functions
+func (r *TypeRegistry) Register(v interface{}, decode DecodeFunc, encode EncodeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[reflect.TypeOf(v)] = typeCodec{decode: decode, encode: encode}
+}
+
+// WithTypeRegistry sets the TypeRegistry used by the Merger, by default it's
+// DefaultTypeRegistry
+func WithTypeRegistry(r *TypeRegistry) Option {
+	return func(m *Merger) {
+		m.types = r
+	}
+}
+
+func (r *TypeRegistry) lookup(t reflect.Type) (typeCodec, bool) {
+	if r == nil {
+		return typeCodec{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.types[t]
+	return c, ok
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isValueType returns true if the type t, a pointer to t or its element, is
+// registered or implements encoding.TextMarshaler. The values of these types
+// are parameters even if they are structs
+func (r *TypeRegistry) isValueType(t reflect.Type) bool {
+	for _, t := range []reflect.Type{t, reflect.PtrTo(t)} {
+		if _, ok := r.lookup(t); ok || t.Implements(textMarshalerType) {
+			return true
+		}
+	}
+	if t.Kind() == reflect.Ptr {
+		return r.isValueType(t.Elem())
+	}
+	return false
+}
+
+// encode returns the parameter value of v if its type, or a pointer to it, is
+// registered or implements encoding.TextMarshaler
+func (r *TypeRegistry) encode(v reflect.Value) (string, bool, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		if c, ok := r.lookup(v.Type()); ok {
+			s, err := c.encode(v.Interface())
+			return s, true, err
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), true, err
+		}
+		v = v.Elem()
+	}
+
+	if c, ok := r.lookup(v.Type()); ok {
+		s, err := c.encode(v.Interface())
+		return s, true, err
+	}
+
+	// The registered pointer types and the methods of the pointer receivers
+	// are used with a copy of the value
+	c, ok := r.lookup(reflect.PtrTo(v.Type()))
+	if !ok && !reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		return "", false, nil
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	if ok {
+		s, err := c.encode(ptr.Interface())
+		return s, true, err
+	}
+	b, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+	return string(b), true, err
+}
+
+// decodeHook returns the mapstructure hook that decodes the strings to the
+// registered types and to the types implementing encoding.TextUnmarshaler
+func (r *TypeRegistry) decodeHook() mapstructure.DecodeHookFuncType {
+	return func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		s, ok := data.(string)
+		if !ok || from.Kind() != reflect.String {
+			return data, nil
+		}
+
+		v, ok, err := r.decode(to, s)
+		if !ok {
+			return data, nil
+		}
+		return v, err
+	}
+}
+
+// decode returns the value of type t of the parameter value s if the type, a
+// pointer to the type or its element is registered or implements
+// encoding.TextUnmarshaler
+func (r *TypeRegistry) decode(t reflect.Type, s string) (interface{}, bool, error) {
+	if c, ok := r.lookup(t); ok {
+		v, err := c.decode(s)
+		return v, true, err
+	}
+
+	if c, ok := r.lookup(reflect.PtrTo(t)); ok {
+		v, err := c.decode(s)
+		if err != nil {
+			return nil, true, err
+		}
+		return reflect.ValueOf(v).Elem().Interface(), true, nil
+	}
+
+	switch {
+	case t.Kind() == reflect.Ptr:
+		v, ok, err := r.decode(t.Elem(), s)
+		if !ok || err != nil {
+			return v, ok, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(reflect.ValueOf(v))
+		return ptr.Interface(), true, nil
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return nil, true, err
+		}
+		return ptr.Elem().Interface(), true, nil
+	}
+
+	return nil, false, nil
+}
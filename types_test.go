@@ -0,0 +1,175 @@
+package merger_test
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johandry/merger"
+)
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(l))), nil
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	if strings.Trim(string(text), "*") != "" {
+		return fmt.Errorf("invalid level %q", text)
+	}
+	*l = level(len(text))
+	return nil
+}
+
+type celsius struct {
+	Degrees float64
+}
+
+type typesConfig struct {
+	Timeout  time.Duration  `json:"timeout"`
+	Started  time.Time      `json:"started"`
+	Addr     net.IP         `json:"addr"`
+	Endpoint *url.URL       `json:"endpoint"`
+	Pattern  *regexp.Regexp `json:"pattern"`
+	Big      *big.Int       `json:"big"`
+	Level    level          `json:"level"`
+	Temp     celsius        `json:"temp"`
+	Retry    *time.Duration `json:"retry"`
+}
+
+func typesRegistry() *merger.TypeRegistry {
+	r := merger.NewTypeRegistry()
+	r.Register(celsius{}, func(s string) (interface{}, error) {
+		var c celsius
+		_, err := fmt.Sscanf(s, "%gC", &c.Degrees)
+		return c, err
+	}, func(v interface{}) (string, error) {
+		return fmt.Sprintf("%gC", v.(celsius).Degrees), nil
+	})
+	return r
+}
+
+func TestMerger_TransformToMapTypes(t *testing.T) {
+	retry := 2 * time.Second
+	v := &typesConfig{
+		Timeout:  90 * time.Second,
+		Started:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Addr:     net.ParseIP("10.0.0.1"),
+		Endpoint: &url.URL{Scheme: "https", Host: "example.com", Path: "/api"},
+		Pattern:  regexp.MustCompile(`^a+$`),
+		Big:      big.NewInt(1 << 62),
+		Level:    3,
+		Temp:     celsius{Degrees: 21.5},
+		Retry:    &retry,
+	}
+	want := map[string]string{
+		"timeout":  "1m30s",
+		"started":  "2020-01-02T03:04:05Z",
+		"addr":     "10.0.0.1",
+		"endpoint": "https://example.com/api",
+		"pattern":  "^a+$",
+		"big":      "4611686018427387904",
+		"level":    "***",
+		"temp":     "21.5C",
+		"retry":    "2s",
+	}
+
+	got, err := merger.New(merger.WithTypeRegistry(typesRegistry())).TransformToMap(v)
+	if err != nil {
+		t.Fatalf("TransformToMap() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TransformToMap() = %v, want %v", got, want)
+	}
+}
+
+func TestMerger_MergeMapTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		srcMap  map[string]string
+		check   func(c typesConfig) error
+		wantErr string
+	}{
+		{name: "built-in types",
+			srcMap: map[string]string{
+				"timeout":  "1m30s",
+				"started":  "2020-01-02T03:04:05Z",
+				"addr":     "10.0.0.1",
+				"endpoint": "https://example.com/api",
+				"pattern":  "^a+$",
+				"big":      "0x10",
+				"retry":    "2s",
+			},
+			check: func(c typesConfig) error {
+				switch {
+				case c.Timeout != 90*time.Second:
+					return fmt.Errorf("timeout = %v", c.Timeout)
+				case !c.Started.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)):
+					return fmt.Errorf("started = %v", c.Started)
+				case !c.Addr.Equal(net.ParseIP("10.0.0.1")):
+					return fmt.Errorf("addr = %v", c.Addr)
+				case c.Endpoint == nil || c.Endpoint.Host != "example.com":
+					return fmt.Errorf("endpoint = %v", c.Endpoint)
+				case c.Pattern == nil || !c.Pattern.MatchString("aaa"):
+					return fmt.Errorf("pattern = %v", c.Pattern)
+				case c.Big == nil || c.Big.Int64() != 16:
+					return fmt.Errorf("big = %v", c.Big)
+				case c.Retry == nil || *c.Retry != 2*time.Second:
+					return fmt.Errorf("retry = %v", c.Retry)
+				}
+				return nil
+			},
+		},
+		{name: "duration in nanoseconds",
+			srcMap: map[string]string{"timeout": "1000"},
+			check: func(c typesConfig) error {
+				if c.Timeout != time.Microsecond {
+					return fmt.Errorf("timeout = %v", c.Timeout)
+				}
+				return nil
+			},
+		},
+		{name: "text unmarshaler and registered type",
+			srcMap: map[string]string{"level": "**", "temp": "-3.5C"},
+			check: func(c typesConfig) error {
+				if c.Level != 2 || c.Temp.Degrees != -3.5 {
+					return fmt.Errorf("level = %v, temp = %v", c.Level, c.Temp)
+				}
+				return nil
+			},
+		},
+		{name: "invalid duration",
+			srcMap:  map[string]string{"timeout": "soon"},
+			wantErr: "timeout",
+		},
+		{name: "invalid text",
+			srcMap:  map[string]string{"level": "high"},
+			wantErr: "level",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := typesConfig{}
+			err := merger.New(merger.WithTypeRegistry(typesRegistry())).MergeMap(&got, tt.srcMap)
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(strings.ToLower(err.Error()), tt.wantErr) {
+					t.Fatalf("MergeMap() error = %v, want an error with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeMap() error = %v", err)
+			}
+			if err := tt.check(got); err != nil {
+				t.Errorf("MergeMap() %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package merger
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// byteUnits are the multipliers of the byte size units, SI and IEC. The units
+// are not case sensitive
+var byteUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"eb":  1e18,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+}
+
+// boolWords are the words, besides the ones accepted by strconv.ParseBool, of
+// the boolean values. They are not case sensitive
+var boolWords = map[string]bool{
+	"yes": true,
+	"y":   true,
+	"on":  true,
+	"no":  false,
+	"n":   false,
+	"off": false,
+}
+
+// unitsDecodeHook is the mapstructure hook that decodes the strings written
+// for humans: the byte sizes (i.e. 10MiB or 1.5GB) and the hexadecimal, octal
+// and binary literals (i.e. 0x1F, 0o17 or 0b101) to integers, the percentages
+// (i.e. 75%) to floats, and yes/no/on/off to booleans. The durations, like
+// 1m30s, are decoded by DefaultTypeRegistry. Any other string is decoded as
+// usual
+func unitsDecodeHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	s, ok := data.(string)
+	if !ok || from.Kind() != reflect.String {
+		return data, nil
+	}
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return data, nil
+	}
+
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok, err := parseInt(s)
+		if !ok {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reflect.Zero(to).OverflowInt(n) {
+			return nil, fmt.Errorf("value %q overflows %s", s, to)
+		}
+		return reflect.ValueOf(n).Convert(to).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok, err := parseInt(s)
+		if !ok {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("value %q is negative, it can't be a %s", s, to)
+		}
+		if reflect.Zero(to).OverflowUint(uint64(n)) {
+			return nil, fmt.Errorf("value %q overflows %s", s, to)
+		}
+		return reflect.ValueOf(uint64(n)).Convert(to).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		if !strings.HasSuffix(s, "%") {
+			return data, nil
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid percentage %q", s)
+		}
+		return reflect.ValueOf(f / 100).Convert(to).Interface(), nil
+	case reflect.Bool:
+		if b, ok := boolWords[strings.ToLower(s)]; ok {
+			return b, nil
+		}
+	}
+
+	return data, nil
+}
+
+// parseInt returns the integer of a byte size or of an integer literal in any
+// base. It returns false if the string is neither of them
+func parseInt(s string) (int64, bool, error) {
+	if n, ok, err := parseByteSize(s); ok {
+		return n, true, err
+	}
+
+	n, err := strconv.ParseInt(s, 0, 64)
+	if err == nil {
+		return n, true, nil
+	}
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return 0, true, fmt.Errorf("value %q overflows int64", s)
+	}
+	return 0, false, nil
+}
+
+// parseByteSize returns the number of bytes of a size like 10MiB, 1.5 GB or
+// 512B. It returns false if the string doesn't end with a byte unit
+func parseByteSize(s string) (int64, bool, error) {
+	i := strings.LastIndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	unit, ok := byteUnits[strings.ToLower(s[i+1:])]
+	if !ok || i < 0 {
+		return 0, false, nil
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(s[:i+1]), 64)
+	if err != nil {
+		return 0, false, nil
+	}
+	size := f * unit
+	if size >= math.MaxInt64 || size <= math.MinInt64 {
+		return 0, true, fmt.Errorf("value %q overflows int64", s)
+	}
+	if size != math.Trunc(size) {
+		return 0, true, fmt.Errorf("value %q is not a whole number of bytes", s)
+	}
+	return int64(size), true, nil
+}
+
+// decodeValues decodes, like decode does, the strings of the nested map to the
+// types of the fields of t, so an invalid value is reported with the parameter
+// name and not with the name of the field. The keys are the parameter names,
+// not normalized
+func decodeValues(t reflect.Type, m map[string]interface{}, parent []string, codec KeyCodec, types *TypeRegistry) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, k := range sortedKeys(m) {
+		path := append(append([]string{}, parent...), k)
+
+		var ft reflect.Type
+		switch t.Kind() {
+		case reflect.Map:
+			ft = t.Elem()
+		case reflect.Struct:
+			field, ok := structFieldByKey(t, k, "")
+			if !ok {
+				continue
+			}
+			ft = field.Type
+		default:
+			return nil
+		}
+
+		if err := decodeValue(ft, m[k], path, codec, types); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeValue(t reflect.Type, v interface{}, path []string, codec KeyCodec, types *TypeRegistry) error {
+	var items []interface{}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return decodeValues(t, value, path, codec, types)
+	case string:
+		if err := decodeString(t, value, types); err != nil {
+			return fmt.Errorf("invalid value of %q: %s", codec.join(path), err)
+		}
+		return nil
+	case []string:
+		for _, item := range value {
+			items = append(items, item)
+		}
+	case []interface{}:
+		items = value
+	default:
+		return nil
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil
+	}
+	for _, item := range items {
+		if err := decodeValue(t.Elem(), item, path, codec, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeString returns the error decoding the string to the type t with the
+// registry or unitsDecodeHook, if any
+func decodeString(t reflect.Type, s string, types *TypeRegistry) error {
+	if _, ok, err := types.decode(t, s); ok {
+		return err
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, err := unitsDecodeHook(reflect.TypeOf(s), t, s)
+	return err
+}
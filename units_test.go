@@ -0,0 +1,108 @@
+package merger_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johandry/merger"
+)
+
+type unitsConfig struct {
+	MaxBody int64         `json:"max_body"`
+	Buffer  uint32        `json:"buffer"`
+	Port    int16         `json:"port"`
+	Mode    int           `json:"mode"`
+	Mask    uint8         `json:"mask"`
+	Ratio   float64       `json:"ratio"`
+	Enabled bool          `json:"enabled"`
+	Debug   bool          `json:"debug"`
+	Timeout time.Duration `json:"timeout"`
+	Sizes   []int         `json:"sizes"`
+}
+
+func TestMergeMapUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		srcMap  map[string]string
+		want    unitsConfig
+		wantErr string
+	}{
+		{name: "human values",
+			srcMap: map[string]string{
+				"max_body": "10MiB",
+				"buffer":   "1.5 KB",
+				"port":     "0x1F90",
+				"mode":     "0o644",
+				"mask":     "0b1010",
+				"ratio":    "75%",
+				"enabled":  "yes",
+				"debug":    "OFF",
+				"timeout":  "1m30s",
+				"sizes":    "[1KiB, 2kb, 0x10]",
+			},
+			want: unitsConfig{
+				MaxBody: 10 << 20,
+				Buffer:  1500,
+				Port:    8080,
+				Mode:    0644,
+				Mask:    10,
+				Ratio:   0.75,
+				Enabled: true,
+				Timeout: 90 * time.Second,
+				Sizes:   []int{1024, 2000, 16},
+			},
+		},
+		{name: "usual values",
+			srcMap: map[string]string{"max_body": "1024", "port": "-1", "ratio": "0.5", "enabled": "true", "debug": "0"},
+			want:   unitsConfig{MaxBody: 1024, Port: -1, Ratio: 0.5, Enabled: true},
+		},
+		{name: "int overflow",
+			srcMap:  map[string]string{"port": "70000"},
+			wantErr: `invalid value of "port": value "70000" overflows int16`,
+		},
+		{name: "size overflow",
+			srcMap:  map[string]string{"buffer": "5GiB"},
+			wantErr: `invalid value of "buffer": value "5GiB" overflows uint32`,
+		},
+		{name: "negative unsigned",
+			srcMap:  map[string]string{"mask": "-0x1"},
+			wantErr: `invalid value of "mask": value "-0x1" is negative`,
+		},
+		{name: "fractional bytes",
+			srcMap:  map[string]string{"max_body": "1.5B"},
+			wantErr: `invalid value of "max_body": value "1.5B" is not a whole number of bytes`,
+		},
+		{name: "invalid percentage",
+			srcMap:  map[string]string{"ratio": "high%"},
+			wantErr: `invalid value of "ratio": invalid percentage "high%"`,
+		},
+		{name: "int64 overflow",
+			srcMap:  map[string]string{"MAX_BODY": "99999999999999999999"},
+			wantErr: `invalid value of "MAX_BODY": value "99999999999999999999" overflows int64`,
+		},
+		{name: "item overflow",
+			srcMap:  map[string]string{"sizes": "[1, 10EiB]"},
+			wantErr: `invalid value of "sizes": value "10EiB" overflows int64`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unitsConfig{}
+			err := merger.MergeMap(&got, tt.srcMap)
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MergeMap() error = %v, want an error with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeMap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
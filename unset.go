@@ -0,0 +1,263 @@
+package merger
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultUnsetValue is the value that, in a source map, resets a field to its
+// zero value or removes a map entry
+const DefaultUnsetValue = "!unset"
+
+// Metadata contains the parameters found in the source maps of a merge. It
+// tells apart the parameters explicitly set to a zero value from the
+// parameters that were not set. The parameters are named with the KeyCodec of
+// the Merger, with the field names in lower case and the map keys as they are
+type Metadata struct {
+	// Set are the parameters with a value in the merged maps
+	Set []string
+	// Unset are the parameters reset with the unset value or a JSON null
+	Unset []string
+	// Sources are the names of the sources, merged with MergeSources, that set
+	// the parameters in Set
+	Sources map[string]string
+
+	keys  map[string]bool
+	codec KeyCodec
+	typ   reflect.Type
+}
+
+// IsSet returns true if the parameter, or any of its fields, was set by the
+// merged maps. The field names are not case sensitive, the map keys are
+func (md *Metadata) IsSet(key string) bool {
+	key = md.normalize(key)
+	for _, k := range md.Set {
+		if k == key || strings.HasPrefix(k, key+md.separator()) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnset returns true if the parameter was reset by the merged maps. The
+// field names are not case sensitive, the map keys are
+func (md *Metadata) IsUnset(key string) bool {
+	key = md.normalize(key)
+	for _, k := range md.Unset {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize returns the parameter name as it's registered
+func (md *Metadata) normalize(key string) string {
+	return md.name(md.keyCodec().path(key))
+}
+
+// keyCodec returns the KeyCodec of the parameter names, DefaultKeyCodec if the
+// Metadata was not used in a merge
+func (md *Metadata) keyCodec() KeyCodec {
+	if len(md.codec.Separator) == 0 {
+		return DefaultKeyCodec
+	}
+	return md.codec
+}
+
+func (md *Metadata) separator() string {
+	return md.keyCodec().Separator
+}
+
+// reset clears the Metadata for a merge into a value of type t
+func (md *Metadata) reset(codec KeyCodec, t reflect.Type) {
+	if md == nil {
+		return
+	}
+	md.codec = codec
+	md.typ = t
+	md.Set = []string{}
+	md.Unset = []string{}
+	md.Sources = map[string]string{}
+	md.keys = map[string]bool{}
+}
+
+// add registers the parameters of a transformed map, from the given source,
+// and the unset paths, the map is merged after, so overrides, any previous one
+func (md *Metadata) add(m map[string]interface{}, unset [][]string, source string) {
+	if md == nil {
+		return
+	}
+
+	for _, path := range unset {
+		key := md.name(path)
+		for k := range md.keys {
+			if strings.HasPrefix(k, key+md.separator()) {
+				delete(md.keys, k)
+				delete(md.Sources, k)
+			}
+		}
+		md.keys[key] = false
+		delete(md.Sources, key)
+	}
+	for _, path := range leafPaths(m, nil) {
+		key := md.name(path)
+		md.keys[key] = true
+		if len(source) != 0 {
+			md.Sources[key] = source
+		} else {
+			delete(md.Sources, key)
+		}
+	}
+
+	md.Set, md.Unset = []string{}, []string{}
+	for k, set := range md.keys {
+		if set {
+			md.Set = append(md.Set, k)
+		} else {
+			md.Unset = append(md.Unset, k)
+		}
+	}
+	sort.Strings(md.Set)
+	sort.Strings(md.Unset)
+}
+
+// name returns the registered name of the path, the segments that are
+// struct fields, or can't be identified, are in lower case
+func (md *Metadata) name(path []string) string {
+	segments := make([]keySegment, 0, len(path))
+	t := md.typ
+	for _, p := range path {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch {
+		case t == nil:
+			segments = append(segments, keySegment{name: strings.ToLower(p)})
+		case t.Kind() == reflect.Struct:
+			segments = append(segments, keySegment{name: strings.ToLower(p)})
+			field, ok := structFieldByKey(t, p, "")
+			t = nil
+			if ok {
+				t = field.Type
+			}
+		case t.Kind() == reflect.Map:
+			segments = append(segments, keySegment{name: p, mapKey: true})
+			t = t.Elem()
+		default:
+			// The keys of the maps inside an interface{}
+			segments = append(segments, keySegment{name: p, mapKey: true})
+		}
+	}
+	return md.keyCodec().encode(segments)
+}
+
+// leafPaths returns the path to every value of the nested map that is not a map
+func leafPaths(m map[string]interface{}, parent []string) [][]string {
+	paths := [][]string{}
+	for k, v := range m {
+		path := append(append([]string{}, parent...), k)
+		if child, ok := v.(map[string]interface{}); ok {
+			paths = append(paths, leafPaths(child, path)...)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// extractUnset removes from the nested map the entries with the unset value
+// or a nil value (a JSON null) and returns their paths
+func extractUnset(m map[string]interface{}, unsetValue string) [][]string {
+	return extractUnsetFrom(m, unsetValue, nil)
+}
+
+func extractUnsetFrom(m map[string]interface{}, unsetValue string, parent []string) [][]string {
+	paths := [][]string{}
+	for k, v := range m {
+		path := append(append([]string{}, parent...), k)
+		switch value := v.(type) {
+		case nil:
+		case string:
+			if len(unsetValue) == 0 || value != unsetValue {
+				continue
+			}
+		case map[string]interface{}:
+			unset := extractUnsetFrom(value, unsetValue, path)
+			paths = append(paths, unset...)
+			// Remove the maps left empty, otherwise they would reset a map entry
+			if len(unset) != 0 && len(value) == 0 {
+				delete(m, k)
+			}
+			continue
+		default:
+			continue
+		}
+		delete(m, k)
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// unsetPath resets the field or removes the map entry referenced by the path.
+// The struct fields are found like mapstructure does, using the mapstructure
+// tag or the field name, not case sensitive
+func unsetPath(v reflect.Value, path []string) {
+	if len(path) == 0 {
+		if v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if field, ok := structFieldByKey(v.Type(), path[0], ""); ok {
+			unsetPath(v.FieldByIndex(field.Index), path[1:])
+		}
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		if len(path) == 1 {
+			v.SetMapIndex(key, reflect.Value{})
+			return
+		}
+		entry := v.MapIndex(key)
+		if !entry.IsValid() {
+			return
+		}
+		// The map entries are not addressable, modify a copy and store it back
+		c := reflect.New(entry.Type()).Elem()
+		c.Set(entry)
+		unsetPath(c, path[1:])
+		v.SetMapIndex(key, c)
+	}
+}
+
+// structFieldByKey returns the exported field of the struct type identified by
+// the key like mapstructure does, using the given tag name, or the
+// mapstructure tag if it's empty, or the field name, not case sensitive
+func structFieldByKey(t reflect.Type, key, tagName string) (reflect.StructField, bool) {
+	key = fieldKey(key)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if fieldKey(fieldTagName(field, tagName)) == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
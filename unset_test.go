@@ -0,0 +1,193 @@
+package merger_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/johandry/merger"
+)
+
+func TestMerger_MergeMapUnset(t *testing.T) {
+	person := func() *Person {
+		return &Person{
+			Name:    "Pepe",
+			Age:     30,
+			Address: Address{City: "San Diego", Country: "US"},
+			Phones: map[string]Phone{
+				"home":   Phone{Number: "858-123-4567", Available: true},
+				"mobile": Phone{Number: "858-987-6543"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opts    []merger.Option
+		dst     interface{}
+		srcMaps []map[string]string
+		want    interface{}
+	}{
+		{name: "unset field",
+			dst: person(),
+			srcMaps: []map[string]string{
+				map[string]string{"Age": "!unset", "Address__City": "!unset"},
+			},
+			want: &Person{
+				Name:    "Pepe",
+				Address: Address{Country: "US"},
+				Phones:  person().Phones,
+			},
+		},
+		{name: "unset struct and map entry",
+			dst: person(),
+			srcMaps: []map[string]string{
+				map[string]string{"Address": "!unset", "Phones__mobile": "!unset", "Phones__home__Number": "!unset"},
+			},
+			want: &Person{
+				Name: "Pepe",
+				Age:  30,
+				Phones: map[string]Phone{
+					"home": Phone{Available: true},
+				},
+			},
+		},
+		{name: "JSON null",
+			dst: person(),
+			srcMaps: []map[string]string{
+				map[string]string{"Address": `{"city": null, "country": "MX"}`},
+			},
+			want: func() *Person {
+				p := person()
+				p.Address = Address{Country: "MX"}
+				return p
+			}(),
+		},
+		{name: "higher priority overrides unset",
+			dst: person(),
+			srcMaps: []map[string]string{
+				map[string]string{"Age": "40"},
+				map[string]string{"Age": "!unset", "Name": "!unset"},
+			},
+			want: func() *Person {
+				p := person()
+				p.Age = 40
+				p.Name = ""
+				return p
+			}(),
+		},
+		{name: "custom unset value",
+			opts: []merger.Option{merger.WithUnsetValue("null")},
+			dst:  person(),
+			srcMaps: []map[string]string{
+				map[string]string{"Name": "null", "Address__Country": "!unset"},
+			},
+			want: func() *Person {
+				p := person()
+				p.Name = ""
+				p.Address.Country = "!unset"
+				return p
+			}(),
+		},
+		{name: "disabled unset value",
+			opts: []merger.Option{merger.WithUnsetValue("")},
+			dst:  person(),
+			srcMaps: []map[string]string{
+				map[string]string{"Name": "!unset"},
+			},
+			want: func() *Person {
+				p := person()
+				p.Name = "!unset"
+				return p
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := merger.New(tt.opts...).MergeMap(tt.dst, tt.srcMaps...); err != nil {
+				t.Fatalf("MergeMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("MergeMap() = %+v, want %+v", tt.dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	md := &merger.Metadata{}
+	m := merger.New(merger.WithMetadata(md))
+
+	dst := &Person{Name: "Pepe", Age: 30}
+	err := m.MergeMap(dst,
+		map[string]string{"Age": "0", "Address__City": "LA"},
+		map[string]string{"Name": "!unset", "Address": "!unset", "Phones__home__Number": "858-123-4567"},
+	)
+	if err != nil {
+		t.Fatalf("MergeMap() error = %v", err)
+	}
+
+	wantSet := []string{"address__city", "age", "phones__home__number"}
+	wantUnset := []string{"address", "name"}
+	if !reflect.DeepEqual(md.Set, wantSet) {
+		t.Errorf("Metadata.Set = %v, want %v", md.Set, wantSet)
+	}
+	if !reflect.DeepEqual(md.Unset, wantUnset) {
+		t.Errorf("Metadata.Unset = %v, want %v", md.Unset, wantUnset)
+	}
+
+	for key, want := range map[string]bool{"Age": true, "Address": true, "Address__Country": false, "Name": false, "Phones": true} {
+		if got := md.IsSet(key); got != want {
+			t.Errorf("Metadata.IsSet(%q) = %v, want %v", key, got, want)
+		}
+	}
+	if !md.IsUnset("name") || md.IsUnset("age") {
+		t.Errorf("Metadata.IsUnset() = %v", md.Unset)
+	}
+}
+
+func TestMetadata_KeyCodec(t *testing.T) {
+	md := &merger.Metadata{}
+	m := merger.New(merger.WithMetadata(md), merger.WithKeyCodec(merger.KeyCodec{Separator: "."}))
+
+	err := m.MergeMap(&Person{}, map[string]string{"Address.City": "LA", "Name": "!unset"})
+	if err != nil {
+		t.Fatalf("MergeMap() error = %v", err)
+	}
+
+	wantSet := []string{"address.city"}
+	if !reflect.DeepEqual(md.Set, wantSet) {
+		t.Errorf("Metadata.Set = %v, want %v", md.Set, wantSet)
+	}
+	for key, want := range map[string]bool{"address.city": true, "Address": true, "address__city": false, "Name": false} {
+		if got := md.IsSet(key); got != want {
+			t.Errorf("Metadata.IsSet(%q) = %v, want %v", key, got, want)
+		}
+	}
+	if !md.IsUnset("name") {
+		t.Errorf("Metadata.IsUnset() = %v", md.Unset)
+	}
+}
+
+func TestMetadata_MapKeys(t *testing.T) {
+	md := &merger.Metadata{}
+	m := merger.New(merger.WithMetadata(md))
+
+	err := m.MergeMap(&Student{}, map[string]string{
+		"Grades__Math__Number": "90",
+		"grades__math__Number": "80",
+		"Address__City":        "LA",
+	})
+	if err != nil {
+		t.Fatalf("MergeMap() error = %v", err)
+	}
+
+	wantSet := []string{"address__city", "grades__Math__number", "grades__math__number"}
+	if !reflect.DeepEqual(md.Set, wantSet) {
+		t.Errorf("Metadata.Set = %v, want %v", md.Set, wantSet)
+	}
+	for key, want := range map[string]bool{"GRADES__Math": true, "grades__math__NUMBER": true, "grades__MATH": false} {
+		if got := md.IsSet(key); got != want {
+			t.Errorf("Metadata.IsSet(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWatchInterval is the time between two checks of the watched files
+// when the Watcher is started with a zero interval
+const DefaultWatchInterval = 2 * time.Second
+
+// Event is sent to the Watcher subscribers every time the configuration is
+// reloaded with a different value
+type Event struct {
+	Old     interface{}
+	New     interface{}
+	Changed []string
+}
+
+// Watcher re-runs the merge of a configuration every time one of the watched
+// files or directories changes. The loaded value is swapped atomically, so
+// the readers always get a complete configuration, and the subscribers are
+// notified with the old and new values. A reload that fails, or returns a
+// value that fails validation, keeps the last good configuration.
+type Watcher struct {
+	load  func() (interface{}, error)
+	paths []string
+
+	value    atomic.Value
+	reloadMu sync.Mutex
+
+	mu          sync.Mutex
+	subscribers []func(Event)
+	onError     func(error)
+	stamps      map[string]fileStamp
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type snapshot struct {
+	v interface{}
+}
+
+// NewWatcher creates a Watcher of the given files or directories. The load
+// function creates and merges a new configuration, it's called once to get
+// the initial value and then every time a watched path changes.
+func NewWatcher(load func() (interface{}, error), paths ...string) (*Watcher, error) {
+	w := &Watcher{
+		load:  load,
+		paths: paths,
+	}
+
+	w.stamps = w.scan()
+
+	v, err := w.loadValid()
+	if err != nil {
+		return nil, err
+	}
+	w.value.Store(snapshot{v})
+
+	return w, nil
+}
+
+// Value returns the last good configuration
+func (w *Watcher) Value() interface{} {
+	return w.value.Load().(snapshot).v
+}
+
+// Subscribe registers a function to call every time the configuration changes
+func (w *Watcher) Subscribe(fn func(Event)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// OnError registers a function to call when a reload started by a change of
+// the watched paths fails
+func (w *Watcher) OnError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onError = fn
+}
+
+// Reload re-runs the merge and, if it succeed, swaps the configuration and
+// notifies the subscribers when something changed
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	newValue, err := w.loadValid()
+	if err != nil {
+		return err
+	}
+
+	oldValue := w.value.Swap(snapshot{newValue}).(snapshot).v
+
+	changed := changedKeys(oldValue, newValue)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	subscribers := make([]func(Event), len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	w.mu.Unlock()
+
+	event := Event{Old: oldValue, New: newValue, Changed: changed}
+	for _, fn := range subscribers {
+		fn(event)
+	}
+
+	return nil
+}
+
+// Start checks the watched paths every interval, in background, and reloads
+// the configuration when any of them changes
+func (w *Watcher) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	w.mu.Lock()
+	if w.stop != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	stop, done := w.stop, w.done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+}
+
+// Stop stops watching the paths, it waits for a running reload to finish
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (w *Watcher) check() {
+	stamps := w.scan()
+	if reflect.DeepEqual(stamps, w.stamps) {
+		return
+	}
+	w.stamps = stamps
+
+	if err := w.Reload(); err != nil {
+		w.mu.Lock()
+		onError := w.onError
+		w.mu.Unlock()
+
+		if onError != nil {
+			onError(err)
+		}
+	}
+}
+
+func (w *Watcher) loadValid() (interface{}, error) {
+	v, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// scan returns the modification time and size of every watched file and of
+// every file inside the watched directories. Missing paths are not included,
+// so removing or creating a file is also a change.
+func (w *Watcher) scan() map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	for _, path := range w.paths {
+		filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			// Ignore the files that cannot be read, they may be in the middle of a change
+			if err != nil {
+				return nil
+			}
+			stamps[p] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return stamps
+}
+
+// changedKeys returns the sorted list of parameters with a different value
+// in the given configurations
+func changedKeys(oldValue, newValue interface{}) []string {
+	changes := Diff(oldValue, newValue)
+
+	changed := make([]string, 0, len(changes))
+	for _, c := range changes {
+		changed = append(changed, c.Path)
+	}
+
+	return changed
+}
@@ -0,0 +1,165 @@
+package merger_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johandry/merger"
+)
+
+type ServerConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func (c *ServerConfig) Validate() error {
+	if c.Port <= 0 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+	return nil
+}
+
+func writeJSONFile(t *testing.T, filename string, m map[string]string) {
+	t.Helper()
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadServerConfig(filename string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]string{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+		cfg := &ServerConfig{}
+		return cfg, merger.MergeMap(cfg, m)
+	}
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "merger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "config.json")
+
+	writeJSONFile(t, filename, map[string]string{"host": "localhost", "port": "8080"})
+
+	w, err := merger.NewWatcher(loadServerConfig(filename), filename)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	var events []merger.Event
+	w.Subscribe(func(e merger.Event) {
+		events = append(events, e)
+	})
+
+	tests := []struct {
+		name        string
+		content     map[string]string
+		want        *ServerConfig
+		wantChanged []string
+		wantErr     bool
+	}{
+		{name: "no change",
+			content: map[string]string{"host": "localhost", "port": "8080"},
+			want:    &ServerConfig{Host: "localhost", Port: 8080},
+		},
+		{name: "change port",
+			content:     map[string]string{"host": "localhost", "port": "9090"},
+			want:        &ServerConfig{Host: "localhost", Port: 9090},
+			wantChanged: []string{"port"},
+		},
+		{name: "invalid keeps last good",
+			content: map[string]string{"host": "example.com", "port": "-1"},
+			want:    &ServerConfig{Host: "localhost", Port: 9090},
+			wantErr: true,
+		},
+		{name: "change host and port",
+			content:     map[string]string{"host": "example.com", "port": "80"},
+			want:        &ServerConfig{Host: "example.com", Port: 80},
+			wantChanged: []string{"host", "port"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			writeJSONFile(t, filename, tt.content)
+
+			err := w.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := w.Value(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() = %+v, want %+v", got, tt.want)
+			}
+			if tt.wantChanged == nil {
+				if len(events) != 0 {
+					t.Errorf("Reload() notified %+v, want no events", events)
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("Reload() notified %d events, want 1", len(events))
+			}
+			if !reflect.DeepEqual(events[0].Changed, tt.wantChanged) {
+				t.Errorf("Event.Changed = %v, want %v", events[0].Changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(events[0].New, tt.want) {
+				t.Errorf("Event.New = %+v, want %+v", events[0].New, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcher_Start(t *testing.T) {
+	dir, err := ioutil.TempDir("", "merger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "config.json")
+
+	writeJSONFile(t, filename, map[string]string{"host": "localhost", "port": "8080"})
+
+	w, err := merger.NewWatcher(loadServerConfig(filename), dir)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	events := make(chan merger.Event, 1)
+	w.Subscribe(func(e merger.Event) {
+		events <- e
+	})
+	w.Start(10 * time.Millisecond)
+	defer w.Stop()
+
+	writeJSONFile(t, filename, map[string]string{"host": "localhost", "port": "9090", "extra": "ignored"})
+
+	select {
+	case e := <-events:
+		want := &ServerConfig{Host: "localhost", Port: 9090}
+		if !reflect.DeepEqual(e.New, want) {
+			t.Errorf("Event.New = %+v, want %+v", e.New, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher did not notify the change")
+	}
+}